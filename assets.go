@@ -0,0 +1,110 @@
+package templar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AssetMount maps a URL prefix (e.g., "/static") to the folder its files are served from.
+type AssetMount struct {
+	Prefix string
+	Folder FSFolder
+}
+
+// AssetURLs builds cache-busting URLs for static assets.  It is exposed to
+// templates as the "asset" function via Funcs:
+//
+//	<link rel="stylesheet" href="{{ asset "css/site.css" }}">
+//	→ /static/css/site.css?v=3f2a9c1b
+//
+// If Manifest has an entry for the asset, the mapped (already versioned) name
+// is used.  Otherwise the first mount containing the file is used and a short
+// hash of its contents is appended.  Hashes are cached until the file's size
+// or modification time changes.
+type AssetURLs struct {
+	// Mounts are searched in order for the asset file.
+	Mounts []AssetMount
+
+	// Manifest optionally maps asset names to versioned file names
+	// (e.g., "css/site.css" → "css/site.3f2a9c1b.css"), served from the first mount.
+	Manifest map[string]string
+
+	mu     sync.Mutex
+	hashes map[string]assetHash
+}
+
+type assetHash struct {
+	size    int64
+	modTime time.Time
+	hash    string
+}
+
+// Funcs returns the template functions provided by this AssetURLs.
+func (a *AssetURLs) Funcs() map[string]any {
+	return map[string]any{"asset": a.URL}
+}
+
+// URL returns the versioned URL for the named asset.
+func (a *AssetURLs) URL(name string) (string, error) {
+	name = strings.TrimPrefix(name, "/")
+	if versioned, ok := a.Manifest[name]; ok && len(a.Mounts) > 0 {
+		return joinURL(a.Mounts[0].Prefix, versioned), nil
+	}
+
+	for i, mount := range a.Mounts {
+		folder := mount.Folder
+		folder.resolve()
+		filePath := path.Join(folder.Path, name)
+		info, err := fs.Stat(folder.FS, filePath)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		hash, err := a.hash(fmt.Sprintf("%d:%s", i, filePath), folder.FS, filePath, info)
+		if err != nil {
+			return "", err
+		}
+		return joinURL(mount.Prefix, name) + "?v=" + hash, nil
+	}
+	return "", fmt.Errorf("asset not found: %s", name)
+}
+
+// hash returns the (cached) content hash of a file.
+func (a *AssetURLs) hash(key string, fsys fs.FS, filePath string, info fs.FileInfo) (string, error) {
+	a.mu.Lock()
+	cached, ok := a.hashes[key]
+	a.mu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.hash, nil
+	}
+
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:8]
+
+	a.mu.Lock()
+	if a.hashes == nil {
+		a.hashes = make(map[string]assetHash)
+	}
+	a.hashes[key] = assetHash{size: info.Size(), modTime: info.ModTime(), hash: hash}
+	a.mu.Unlock()
+	return hash, nil
+}
+
+// joinURL joins a URL prefix and a relative path with exactly one slash.
+func joinURL(prefix, name string) string {
+	prefix = strings.Trim(prefix, "/")
+	name = strings.TrimPrefix(name, "/")
+	if prefix == "" {
+		return "/" + name
+	}
+	return "/" + prefix + "/" + name
+}
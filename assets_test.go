@@ -0,0 +1,40 @@
+package templar
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAssetURLs verifies that asset URLs use the mount prefix, carry a content
+// hash that changes with the file, and honor manifest entries.
+func TestAssetURLs(t *testing.T) {
+	mfs := NewMemFS()
+	mfs.SetFile("public/css/site.css", []byte("body{}"))
+
+	assets := &AssetURLs{Mounts: []AssetMount{
+		{Prefix: "/missing", Folder: FSFolder{FS: mfs, Path: "nothere"}},
+		{Prefix: "/static/", Folder: FSFolder{FS: mfs, Path: "public"}},
+	}}
+
+	first, err := assets.URL("css/site.css")
+	if err != nil {
+		t.Fatalf("URL failed: %v", err)
+	}
+	if !strings.HasPrefix(first, "/static/css/site.css?v=") || len(first) != len("/static/css/site.css?v=")+8 {
+		t.Errorf("Unexpected URL: %q", first)
+	}
+
+	mfs.SetFile("public/css/site.css", []byte("body{color:red}"))
+	if second, _ := assets.URL("/css/site.css"); second == first {
+		t.Errorf("Expected hash to change after file changed, got %q twice", second)
+	}
+
+	if _, err := assets.URL("js/missing.js"); err == nil {
+		t.Error("Expected error for missing asset")
+	}
+
+	assets.Manifest = map[string]string{"app.js": "app.abc123.js"}
+	if got, _ := assets.URL("app.js"); got != "/missing/app.abc123.js" {
+		t.Errorf("Manifest URL = %q", got)
+	}
+}
@@ -0,0 +1,77 @@
+package templar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// RenderBatch renders each of roots (loaded by name via the group's Loader)
+// as html with data, passing the output of every successful render to emit,
+// eg to write it to a file when generating a static site.
+//
+// Unlike rendering roots one by one, a failing root does not stop the batch.
+// Each root is isolated with a recover, so a func (or PANIC_ON_TEMPLAR_ERRORS)
+// panicking in one page is reported as that page's error instead of killing
+// the build.  Failed roots are not emitted.
+//
+// Returns the errors (including emit errors) keyed by root, which is empty if
+// every root rendered.
+func (t *TemplateGroup) RenderBatch(roots []string, data any, emit func(root string, output []byte) error) map[string]error {
+	errs := make(map[string]error)
+	for _, root := range roots {
+		var buf bytes.Buffer
+		err := t.renderIsolated(&buf, root, data)
+		if err == nil {
+			err = emit(root, buf.Bytes())
+		}
+		if err != nil {
+			slog.Error("error rendering batch root", "root", root, "error", err)
+			errs[root] = err
+		}
+	}
+	return errs
+}
+
+// renderIsolated loads and renders a single root, converting any panic into
+// an error.
+func (t *TemplateGroup) renderIsolated(buf *bytes.Buffer, root string, data any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic rendering %s: %v", root, r)
+		}
+	}()
+	templates, err := t.Loader.Load(root, "")
+	if err != nil {
+		return err
+	}
+	return t.RenderHtmlTemplate(buf, templates[0], "", data, nil)
+}
+
+// RenderEachDefine renders every template defined at the top level of root's
+// file (not in the files it includes) to its own output, keyed by name, eg
+// to preview each component of a file in a gallery.  sampleData holds the
+// data to render each define with, keyed by define name; defines without
+// sample data are rendered with nil.
+//
+// The file is compiled once.  A define that fails to render is left out of
+// the outputs and its error is included in the returned (joined) error, so
+// one broken component does not hide the others.
+func (t *TemplateGroup) RenderEachDefine(root *Template, sampleData map[string]any) (map[string]string, error) {
+	out, err := t.PreProcessHtmlTemplate(root, nil)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make(map[string]string)
+	var errs []error
+	for _, name := range fileDefines(root) {
+		var buf bytes.Buffer
+		if err := t.executeHtml(&buf, root, out, name, sampleData[name]); err != nil {
+			errs = append(errs, fmt.Errorf("rendering %q: %w", name, err))
+			continue
+		}
+		outputs[name] = buf.String()
+	}
+	return outputs, errors.Join(errs...)
+}
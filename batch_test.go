@@ -0,0 +1,78 @@
+package templar
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestRenderBatch verifies that a batch render carries on past failing and
+// panicking roots, reporting their errors and emitting only the good pages.
+func TestRenderBatch(t *testing.T) {
+	group := NewTemplateGroup()
+	group.AddFuncs(map[string]any{"explode": func() string { panic("boom") }})
+	group.Loader = NewMapLoader(map[string]string{
+		"a.html":       `A`,
+		"broken.html":  `{{ template "missing" }}`,
+		"explode.html": `{{ explode }}`,
+		"b.html":       `B`,
+	})
+	t.Setenv("PANIC_ON_TEMPLAR_ERRORS", "true")
+
+	emitted := make(map[string]string)
+	errs := group.RenderBatch([]string{"a.html", "broken.html", "explode.html", "nope.html", "b.html"}, nil, func(root string, output []byte) error {
+		emitted[root] = string(output)
+		return nil
+	})
+
+	if want := map[string]string{"a.html": "A", "b.html": "B"}; !reflect.DeepEqual(emitted, want) {
+		t.Errorf("emitted %v, want %v", emitted, want)
+	}
+	for _, root := range []string{"broken.html", "explode.html", "nope.html"} {
+		if errs[root] == nil {
+			t.Errorf("Expected an error for %s", root)
+		}
+	}
+	if len(errs) != 3 {
+		t.Errorf("Expected 3 errors, got %v", errs)
+	}
+
+	errs = group.RenderBatch([]string{"a.html"}, nil, func(root string, output []byte) error {
+		return errors.New("disk full")
+	})
+	if errs["a.html"] == nil {
+		t.Error("Expected emit errors to be reported")
+	}
+}
+
+// TestRenderEachDefine verifies that every define in a file (but not in its
+// includes) is rendered separately with its sample data.
+func TestRenderEachDefine(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"components.html": `{{# include "base.html" #}}` +
+			`{{ define "button" }}<button>{{ .Label }}</button>{{ end }}` +
+			`{{ define "card" }}<div>{{ template "frame" .Title }}</div>{{ end }}` +
+			`{{ define "broken" }}{{ template "missing" }}{{ end }}` +
+			`{{ define "divider" }}<hr>{{ end }}`,
+		"base.html": `{{ define "frame" }}[{{ . }}]{{ end }}`,
+	})
+	root := group.MustLoad("components.html", "")[0]
+
+	outputs, err := group.RenderEachDefine(root, map[string]any{
+		"button": map[string]any{"Label": "OK"},
+		"card":   map[string]any{"Title": "Hello"},
+	})
+	want := map[string]string{
+		"button":  "<button>OK</button>",
+		"card":    "<div>[Hello]</div>",
+		"divider": "<hr>",
+	}
+	if !reflect.DeepEqual(outputs, want) {
+		t.Errorf("got %v, want %v", outputs, want)
+	}
+	if err == nil || !strings.Contains(err.Error(), `rendering "broken"`) {
+		t.Errorf("expected error for broken, got %v", err)
+	}
+}
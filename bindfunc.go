@@ -0,0 +1,130 @@
+package templar
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+)
+
+// errorType is the reflect type of the error interface.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// bindFuncs adds the funcs created by bindfunc directives to bound.  The
+// func a binding calls is looked up in bound (so bindings can build on each
+// other), then in the render's funcs and then in the group's Funcs.
+func (t *TemplateGroup) bindFuncs(bindings []FuncBinding, funcs map[string]any, bound map[string]any) error {
+	for _, b := range bindings {
+		base, ok := bound[b.Func]
+		if !ok {
+			base, ok = funcs[b.Func]
+		}
+		if !ok {
+			base, ok = t.Funcs[b.Func]
+		}
+		if !ok {
+			return fmt.Errorf("bindfunc %q: function %q not defined", b.Name, b.Func)
+		}
+		fn, err := bindFunc(b, base)
+		if err != nil {
+			return err
+		}
+		bound[b.Name] = fn
+	}
+	return nil
+}
+
+// withFuncs returns funcs with bound added, or funcs itself if nothing is
+// bound.
+func withFuncs(funcs map[string]any, bound map[string]any) map[string]any {
+	if len(bound) == 0 {
+		return funcs
+	}
+	merged := make(map[string]any, len(funcs)+len(bound))
+	maps.Copy(merged, funcs)
+	maps.Copy(merged, bound)
+	return merged
+}
+
+// bindFunc returns a func that calls base with b.Args followed by its own
+// arguments.  It fails if base cannot take b.Args.
+func bindFunc(b FuncBinding, base any) (any, error) {
+	fn := reflect.ValueOf(base)
+	typ := fn.Type()
+	if typ.Kind() != reflect.Func {
+		return nil, fmt.Errorf("bindfunc %q: %q is not a function", b.Name, b.Func)
+	}
+	if typ.NumOut() == 0 || typ.NumOut() > 2 || (typ.NumOut() == 2 && typ.Out(1) != errorType) {
+		return nil, fmt.Errorf("bindfunc %q: %q must return a value, optionally with an error", b.Name, b.Func)
+	}
+	if !typ.IsVariadic() && len(b.Args) > typ.NumIn() {
+		return nil, fmt.Errorf("bindfunc %q: %q takes %d arguments, %d bound", b.Name, b.Func, typ.NumIn(), len(b.Args))
+	}
+
+	fixed := make([]reflect.Value, len(b.Args))
+	for i, arg := range b.Args {
+		v, err := argValue(arg, paramType(typ, i))
+		if err != nil {
+			return nil, fmt.Errorf("bindfunc %q: argument %d of %q: %w", b.Name, i+1, b.Func, err)
+		}
+		fixed[i] = v
+	}
+
+	return func(args ...any) (any, error) {
+		in := append([]reflect.Value{}, fixed...)
+		for _, arg := range args {
+			if !typ.IsVariadic() && len(in) >= typ.NumIn() {
+				return nil, fmt.Errorf("%s: too many arguments (%q takes %d, %d bound)", b.Name, b.Func, typ.NumIn(), len(b.Args))
+			}
+			v, err := argValue(arg, paramType(typ, len(in)))
+			if err != nil {
+				return nil, fmt.Errorf("%s: argument %d: %w", b.Name, len(in)-len(fixed)+1, err)
+			}
+			in = append(in, v)
+		}
+		minArgs := typ.NumIn()
+		if typ.IsVariadic() {
+			minArgs--
+		}
+		if len(in) < minArgs {
+			return nil, fmt.Errorf("%s: not enough arguments (%q takes %d, %d bound)", b.Name, b.Func, minArgs, len(b.Args))
+		}
+		out := fn.Call(in)
+		if len(out) == 2 && !out[1].IsNil() {
+			return nil, out[1].Interface().(error)
+		}
+		return out[0].Interface(), nil
+	}, nil
+}
+
+// paramType returns the type of the i'th argument of a func type.
+func paramType(typ reflect.Type, i int) reflect.Type {
+	if typ.IsVariadic() && i >= typ.NumIn()-1 {
+		return typ.In(typ.NumIn() - 1).Elem()
+	}
+	return typ.In(i)
+}
+
+// argValue converts arg to a value of type typ, allowing numeric conversions
+// (directive literals like 2 are ints) but not eg ints to strings.
+func argValue(arg any, typ reflect.Type) (reflect.Value, error) {
+	if arg == nil {
+		switch typ.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+			return reflect.Zero(typ), nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot use nil as %s", typ)
+	}
+	v := reflect.ValueOf(arg)
+	if v.Type().AssignableTo(typ) {
+		return v, nil
+	}
+	if isNumeric(v.Kind()) && isNumeric(typ.Kind()) {
+		return v.Convert(typ), nil
+	}
+	return reflect.Value{}, fmt.Errorf("cannot use %v (%s) as %s", arg, v.Type(), typ)
+}
+
+// isNumeric reports whether kind is an integer or float kind.
+func isNumeric(kind reflect.Kind) bool {
+	return (kind >= reflect.Int && kind <= reflect.Uint64) || kind == reflect.Float32 || kind == reflect.Float64
+}
@@ -0,0 +1,67 @@
+package templar
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestBindFunc verifies that bindfunc creates funcs with leading arguments
+// fixed, in both html and text templates.
+func TestBindFunc(t *testing.T) {
+	newGroup := func(files map[string]string) *TemplateGroup {
+		group := NewTemplateGroup()
+		group.AddFuncs(map[string]any{
+			"currency": func(code string, amount float64) string { return fmt.Sprintf("%s %.2f", code, amount) },
+			"join":     func(sep string, parts ...string) string { return strings.Join(parts, sep) },
+			"round": func(places int, v float64) (string, error) {
+				if places < 0 {
+					return "", fmt.Errorf("negative places")
+				}
+				return fmt.Sprintf("%.*f", places, v), nil
+			},
+		})
+		group.Loader = NewMapLoader(files)
+		return group
+	}
+
+	files := map[string]string{
+		"page.html": `{{# include "helpers.html" #}}{{# bindfunc "usd" "currency" "USD" #}}{{# bindfunc "csv" "join" "," #}}` +
+			`{{ usd .Price }}|{{ csv "a" "b" }}|{{ round2 .Price }}`,
+		"helpers.html": `{{# bindfunc "round2" "round" 2 #}}`,
+	}
+	group := newGroup(files)
+	data := map[string]any{"Price": 3.5}
+	want := "USD 3.50|a,b|3.50"
+	if got := renderWith(t, group, "page.html", data); got != want {
+		t.Errorf("html: got %q, want %q", got, want)
+	}
+	var buf bytes.Buffer
+	if err := group.RenderTextTemplate(&buf, group.MustLoad("page.html", "")[0], "", data, nil); err != nil {
+		t.Fatalf("RenderTextTemplate failed: %v", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+
+	errorCases := []struct {
+		name, source, want string
+	}{
+		{"UnknownFunc", `{{# bindfunc "usd" "money" "USD" #}}`, `bindfunc "usd": function "money" not defined`},
+		{"TooManyArgs", `{{# bindfunc "usd" "currency" "USD" 1 2 #}}`, `"currency" takes 2 arguments, 3 bound`},
+		{"WrongType", `{{# bindfunc "usd" "currency" 1 #}}`, `argument 1 of "currency": cannot use 1 (int) as string`},
+		{"CallArity", `{{# bindfunc "usd" "currency" "USD" #}}{{ usd 1 2 }}`, `usd: too many arguments`},
+		{"FuncError", `{{# bindfunc "r" "round" -1 #}}{{ r 1.5 }}`, `negative places`},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			group := newGroup(map[string]string{"page.html": tc.source})
+			var buf bytes.Buffer
+			err := group.RenderHtmlTemplate(&buf, group.MustLoad("page.html", "")[0], "", nil, nil)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package templar
+
+import (
+	"bytes"
+	"sync"
+)
+
+// MaxPooledBufferSize is the largest capacity (in bytes) of the buffers that
+// are reused across renders.  Preprocessing and rendering take their
+// intermediate buffers from a pool to reduce allocations in high throughput
+// servers; buffers that grew beyond this (eg for one outsized page) are
+// dropped rather than kept alive in the pool.  Set it to 0 to disable
+// pooling.  It must not be changed while rendering.
+var MaxPooledBufferSize = 64 << 10
+
+// bufferPool holds the buffers reused by getBuffer and putBuffer.
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns buf to the pool unless it is larger than
+// MaxPooledBufferSize.  buf (and slices of its contents) must not be used
+// afterwards.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= MaxPooledBufferSize {
+		buf.Reset()
+		bufferPool.Put(buf)
+	}
+}
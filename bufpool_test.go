@@ -0,0 +1,46 @@
+package templar
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestBufferPool verifies that pooled buffers reduce the bytes allocated per
+// render, and that disabling pooling still renders the same output.
+func TestBufferPool(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":  `{{# meta "layout" "base.html" #}}{{# include "parts.html" #}}{{ template "body" . }}`,
+		"parts.html": `{{ define "body" }}` + strings.Repeat("<p>lorem ipsum dolor sit amet</p>\n", 500) + `{{ end }}`,
+		"base.html":  `<main>{{ .Content }}</main>`,
+	})
+	templates, _ := group.Loader.Load("page.html", "")
+	root := templates[0]
+
+	defer func(size int) { MaxPooledBufferSize = size }(MaxPooledBufferSize)
+	allocated := func(name string) int64 {
+		stats := NewMemStats()
+		group.RenderHtmlTemplate(io.Discard, root, "", nil, nil) // warm up the pool
+		stats.SnapshotWithGC("before")
+		for range 20 {
+			if err := group.RenderHtmlTemplate(io.Discard, root, "", nil, nil); err != nil {
+				t.Fatalf("%s: render failed: %v", name, err)
+			}
+		}
+		stats.Snapshot("after")
+		delta := stats.Delta("before", "after")
+		t.Logf("%s: %d bytes allocated per render", name, delta.TotalAllocDelta/20)
+		return delta.TotalAllocDelta
+	}
+
+	pooled := allocated("pooled")
+	MaxPooledBufferSize = 0
+	unpooled := allocated("unpooled")
+	if pooled >= unpooled {
+		t.Errorf("expected fewer bytes allocated with pooling, got %d vs %d", pooled, unpooled)
+	}
+	if got := renderWith(t, group, "page.html", nil); !strings.HasPrefix(got, "<main><p>lorem") {
+		t.Errorf("unexpected output without pooling: %.40q", got)
+	}
+}
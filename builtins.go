@@ -0,0 +1,117 @@
+package templar
+
+import (
+	"bytes"
+	"fmt"
+	htmpl "html/template"
+	"io"
+	"log/slog"
+	"strconv"
+	ttmpl "text/template"
+	"text/template/parse"
+)
+
+// Builtin functions are available to every template rendered by a group.
+// Unlike user Funcs they are bound to the compiled template set so they can
+// execute other templates by name.
+//
+//   - tryTemplate "name" data ["fallback"]: executes the named template and
+//     returns its output.  If it fails, the error is logged and the fallback
+//     template (if given) is rendered instead, otherwise an empty string.
+//     This lets a page composed of independent widgets survive one bad widget.
+//   - t "key" args...: translates key using the group's Translator (see i18n.go),
+//     for the locale in the render data.  Returns key if no Translator is set.
+//   - rawContent "html|js|css" content: marks content as trusted for that
+//     escaping context.  Emitted by the raw_include_html/js/css directives.
+
+// htmlBuiltins returns the builtin functions bound to an html template set.
+func htmlBuiltins(out *htmpl.Template) htmpl.FuncMap {
+	return htmpl.FuncMap{
+		"tryTemplate": func(name string, data any, fallback ...string) htmpl.HTML {
+			return htmpl.HTML(tryExecute(out.ExecuteTemplate, name, data, fallback))
+		},
+		// Rebound with the render's locale when rendering
+		"t":          untranslated,
+		"rawContent": typedContent,
+	}
+}
+
+// textBuiltins returns the builtin functions bound to a text template set.
+func textBuiltins(out *ttmpl.Template) ttmpl.FuncMap {
+	return ttmpl.FuncMap{
+		"tryTemplate": func(name string, data any, fallback ...string) string {
+			return tryExecute(out.ExecuteTemplate, name, data, fallback)
+		},
+		"t": untranslated,
+		"rawContent": func(kind string, content string) string {
+			return content
+		},
+	}
+}
+
+// typedContent marks content inlined by the raw_include_html, raw_include_js
+// and raw_include_css directives as trusted for the given escaping context.
+func typedContent(kind string, content string) (any, error) {
+	switch kind {
+	case "html":
+		return htmpl.HTML(content), nil
+	case "js":
+		return htmpl.JS(content), nil
+	case "css":
+		return htmpl.CSS(content), nil
+	}
+	return nil, fmt.Errorf("rawContent: unknown content kind: %s", kind)
+}
+
+// untranslated is the placeholder "t" func used until a render binds the real one.
+func untranslated(key string, args ...any) string {
+	return key
+}
+
+// tryExecute runs the named template falling back to the first fallback
+// template (if any) when it fails.  Errors are logged and never returned.
+func tryExecute(execute func(w io.Writer, name string, data any) error, name string, data any, fallback []string) string {
+	var buf bytes.Buffer
+	err := execute(&buf, name, data)
+	if err == nil {
+		return buf.String()
+	}
+	slog.Error("tryTemplate: error rendering template", "name", name, "error", err)
+	buf.Reset()
+	if len(fallback) > 0 && fallback[0] != "" {
+		if err := execute(&buf, fallback[0], data); err != nil {
+			slog.Error("tryTemplate: error rendering fallback", "name", fallback[0], "error", err)
+			return ""
+		}
+	}
+	return buf.String()
+}
+
+// transformBuiltinArgs applies transform to the template names passed as
+// string literals to builtins such as tryTemplate, eg namespace resolution so
+// that {{ tryTemplate "widget" . }} inside a namespaced file calls
+// "NS:widget".
+func transformBuiltinArgs(tree *parse.Tree, transform func(string) string) {
+	if tree == nil || tree.Root == nil {
+		return
+	}
+	WalkNodes(tree.Root, func(node parse.Node) {
+		cmd, ok := node.(*parse.CommandNode)
+		if !ok || len(cmd.Args) == 0 {
+			return
+		}
+		ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+		if !ok || ident.Ident != "tryTemplate" {
+			return
+		}
+		// Argument 1 is the template name, argument 3 the fallback name
+		for _, i := range []int{1, 3} {
+			if i < len(cmd.Args) {
+				if str, ok := cmd.Args[i].(*parse.StringNode); ok {
+					str.Text = transform(str.Text)
+					str.Quoted = strconv.Quote(str.Text)
+				}
+			}
+		}
+	})
+}
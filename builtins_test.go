@@ -0,0 +1,47 @@
+package templar
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestTryTemplate verifies that tryTemplate renders a widget normally, falls
+// back to another template on error, and renders nothing when no fallback is given.
+func TestTryTemplate(t *testing.T) {
+	files := map[string]string{
+		"page.html": `{{ define "ok" }}OK{{ end }}` +
+			`{{ define "bad" }}{{ index .Items 5 }}{{ end }}` +
+			`{{ define "oops" }}Oops{{ end }}` +
+			`[{{ tryTemplate "ok" . }}][{{ tryTemplate "bad" . "oops" }}][{{ tryTemplate "bad" . }}]`,
+	}
+	got := loadAndRender(t, files, "page.html", "", map[string]any{"Items": []int{}})
+	if want := "[OK][Oops][]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestTryTemplate_Namespaced verifies that template names passed to
+// tryTemplate inside a namespaced file are resolved within that namespace.
+func TestTryTemplate_Namespaced(t *testing.T) {
+	files := map[string]string{
+		"page.html":    `{{# namespace "W" "widgets.html" #}}{{ template "W:panel" . }}`,
+		"widgets.html": `{{ define "panel" }}[{{ tryTemplate "body" . }}]{{ end }}{{ define "body" }}Body{{ end }}`,
+	}
+	got := loadAndRender(t, files, "page.html", "", nil)
+	if want := "[Body]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestTryTemplate_Text verifies that tryTemplate is also available to text templates.
+func TestTryTemplate_Text(t *testing.T) {
+	group := NewTemplateGroup()
+	root := &Template{RawSource: []byte(`{{ define "a" }}<a>{{ end }}{{ tryTemplate "a" . }}{{ tryTemplate "missing" . }}`)}
+	var buf bytes.Buffer
+	if err := group.RenderTextTemplate(&buf, root, "", nil, nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got, want := buf.String(), "<a>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
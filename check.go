@@ -0,0 +1,65 @@
+package templar
+
+import (
+	"maps"
+)
+
+// ParseCheck loads each of the given templates and verifies that it parses,
+// without resolving its includes or rendering it.  This catches Go template
+// syntax mistakes (unclosed actions, unknown functions, bad pipelines) as well
+// as malformed directives, up front rather than on first render.
+//
+// Each returned error is a *TemplateError identifying the file and, where
+// possible, the line of the problem.  An empty result means every template parsed.
+func (t *TemplateGroup) ParseCheck(paths []string) (errs []error) {
+	// Namespace-scoped funcs are only bound when rendering under a namespace,
+	// but a file checked in isolation may rely on them
+	funcs := make(map[string]any)
+	for _, nsFuncs := range t.namespaceFuncs {
+		maps.Copy(funcs, nsFuncs)
+	}
+
+	for _, path := range paths {
+		templates, err := t.Loader.Load(path, "")
+		if err != nil {
+			errs = append(errs, &TemplateError{Path: path, Err: err})
+			continue
+		}
+		for _, tmpl := range templates {
+			if err := t.parseCheck(tmpl, funcs); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return
+}
+
+// parseCheck preprocesses the directives of a single template (skipping its
+// includes) and parses the result.
+func (t *TemplateGroup) parseCheck(tmpl *Template, funcs map[string]any) error {
+	name := tmpl.Path
+	if name == "" {
+		name = tmpl.Name
+	}
+
+	w := Walker{
+		Loader:             t.Loader,
+		Data:               t.PreprocessData,
+		TrimDirectiveLines: t.TrimDirectiveLines,
+		Bundles:            t.bundles,
+		FoundInclude:       func(included string) bool { return true },
+	}
+	if err := w.Walk(tmpl); err != nil {
+		return &TemplateError{Path: name, Line: errorLine(err, ""), Err: err}
+	}
+
+	// Funcs created by bindfunc directives are only known once they have run
+	bound := make(map[string]any)
+	if err := t.bindFuncs(tmpl.FuncBindings, funcs, bound); err != nil {
+		return &TemplateError{Path: name, Err: err}
+	}
+	if _, err := t.NewHtmlTemplate(name, withFuncs(funcs, bound)).Parse(tmpl.ParsedSource); err != nil {
+		return &TemplateError{Path: name, Line: errorLine(err, name), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package templar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestParseCheck verifies that ParseCheck reports syntax errors with the file
+// and line they occur on, ignores includes, and passes valid templates.
+func TestParseCheck(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"good.html":    "{{# include \"missing.html\" #}}\n{{ define \"x\" }}{{ .Name }}{{ end }}",
+		"bad.html":     "line one\nline two\n{{ if .Name }}unclosed",
+		"unknown.html": "{{ nosuchfunc }}",
+	})
+
+	errs := group.ParseCheck([]string{"good.html", "bad.html", "unknown.html", "absent.html"})
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, got %d: %v", len(errs), errs)
+	}
+
+	var te *TemplateError
+	if !errors.As(errs[0], &te) || te.Path != "bad.html" || te.Line != 3 {
+		t.Errorf("Expected bad.html:3, got %v", errs[0])
+	}
+	if !errors.As(errs[1], &te) || te.Path != "unknown.html" || te.Line != 1 {
+		t.Errorf("Expected unknown.html:1, got %v", errs[1])
+	}
+	if !errors.As(errs[2], &te) || te.Path != "absent.html" || !errors.Is(errs[2], TemplateNotFound) {
+		t.Errorf("Expected TemplateNotFound for absent.html, got %v", errs[2])
+	}
+}
+
+// TestParseCheck_Bundles tests that bundles registered with the group are
+// known when parsing, as they are when rendering.
+func TestParseCheck_Bundles(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":   `{{# bundle "forms" #}}{{ template "input" }}`,
+		"input.html":  `{{ define "input" }}<input>{{ end }}`,
+		"broken.html": `{{# bundle "missing" #}}`,
+	})
+	group.RegisterBundle("forms", []string{"input.html"})
+
+	if errs := group.ParseCheck([]string{"page.html"}); len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+	errs := group.ParseCheck([]string{"broken.html"})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "bundle not found: missing") {
+		t.Errorf("Expected an error for an unregistered bundle, got %v", errs)
+	}
+}
+
+// TestParseCheck_BoundFuncs tests that funcs created by bindfunc directives
+// are known when parsing, as they are when rendering.
+func TestParseCheck_BoundFuncs(t *testing.T) {
+	group := NewTemplateGroup()
+	group.AddFuncs(map[string]any{"money": func(currency string, n int) string { return fmt.Sprintf("%s%d", currency, n) }})
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":   `{{# bindfunc "usd" "money" "USD" #}}{{ usd 3 }}`,
+		"broken.html": `{{# bindfunc "usd" "nosuchfunc" "USD" #}}{{ usd 3 }}`,
+	})
+
+	if errs := group.ParseCheck([]string{"page.html"}); len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+	if got := renderWith(t, group, "page.html", nil); got != "USD3" {
+		t.Errorf("Expected USD3, got %q", got)
+	}
+	errs := group.ParseCheck([]string{"broken.html"})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `function "nosuchfunc" not defined`) {
+		t.Errorf("Expected an error for binding an undefined func, got %v", errs)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/panyam/templar"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkPathFlag       string
+	checkExtensionsFlag string
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check [dir]",
+	Short: "Compile every template in a directory and report failures",
+	Long: `Compile every template file under a directory, resolving includes,
+namespaces, extends and overrides exactly as a render would, and report any
+that fail to compile.  Unlike debug, which analyzes a single file, this catches
+real compile errors across the whole tree, eg an extend referencing an
+undefined template.
+
+Each file is compiled as a root on its own, with the directory (and any extra
+--path folders) as the search path.  The standard funcs (dict, default, ...)
+are available as with serve, and other funcs are assumed to be provided by
+the application.  Exits non-zero if any file fails.
+Defaults to the current directory.
+
+Examples:
+  # Check all templates under ./templates
+  templar check templates
+
+  # Also resolve includes from a shared folder
+  templar check templates -p ../shared`,
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         runCheck,
+	SilenceUsage: true,
+}
+
+func init() {
+	checkCmd.Flags().StringVarP(&checkPathFlag, "path", "p", "", "Comma-separated additional search paths for templates")
+	checkCmd.Flags().StringVar(&checkExtensionsFlag, "ext", "html,htm,tmpl", "Comma-separated template file extensions to check")
+
+	rootCmd.AddCommand(checkCmd)
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	extensions := make(map[string]bool)
+	for _, ext := range strings.Split(checkExtensionsFlag, ",") {
+		extensions["."+strings.TrimPrefix(strings.TrimSpace(ext), ".")] = true
+	}
+	searchPaths := []string{dir}
+	for _, p := range strings.Split(checkPathFlag, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			searchPaths = append(searchPaths, p)
+		}
+	}
+
+	passed, failed := 0, 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !extensions[filepath.Ext(path)] {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if err := checkTemplate(filepath.ToSlash(rel), searchPaths); err != nil {
+			failed++
+			fmt.Printf("FAIL %s\n     %v\n", path, err)
+		} else {
+			passed++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d passed, %d failed\n", passed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d template(s) failed to compile", failed)
+	}
+	return nil
+}
+
+// checkTemplate compiles a single template as a root in a fresh group so
+// that errors in one file do not leak into the others.  The group has the
+// standard funcs, as with serve, and other funcs the template calls (which
+// the application is expected to provide) are stubbed.
+func checkTemplate(name string, searchPaths []string) error {
+	group := templar.NewTemplateGroup()
+	group.AddFuncs(templar.StandardFuncs())
+	group.Loader = templar.NewFileSystemLoader(templar.LocalFolders(searchPaths...)...)
+
+	templates, err := group.Loader.Load(name, "")
+	if err != nil {
+		return err
+	}
+	for _, tmpl := range templates {
+		missing, err := group.RequiredFuncs(tmpl)
+		if err != nil {
+			return err
+		}
+		stubs := make(map[string]any)
+		for _, fn := range missing {
+			stubs[fn] = func(...any) any { return nil }
+		}
+		if _, err := group.PreProcessHtmlTemplate(tmpl, stubs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/panyam/templar"
@@ -22,8 +23,10 @@ Features:
   - Detect dependency cycles
   - Show template definitions and references
   - Output GraphViz DOT format for visualization
+  - Write an interactive HTML page of the graph (no GraphViz needed)
   - Flatten/preprocess templates
   - Trace path resolution
+  - Report templates dropped by tree-shaking
 
 Config file options (debug section):
   debug:
@@ -32,13 +35,18 @@ Config file options (debug section):
     cycles: true
     defines: false
     refs: false
+    depth: 0
+    shaking: false
 
 Examples:
   templar debug -p templates,../shared WorldListingPage.html
   templar debug -v --cycles WorldListingPage.html
   templar debug --dot WorldListingPage.html > deps.dot
+  templar debug --html deps.html WorldListingPage.html
   templar debug --flatten WorldListingPage.html
-  templar debug --trace WorldListingPage.html`,
+  templar debug --trace WorldListingPage.html
+  templar debug --depth 2 WorldListingPage.html
+  templar debug --shaking WorldListingPage.html`,
 	Args: cobra.ExactArgs(1),
 	Run:  runDebug,
 }
@@ -50,8 +58,11 @@ func init() {
 	debugCmd.Flags().Bool("refs", false, "Show template references")
 	debugCmd.Flags().Bool("cycles", true, "Detect dependency cycles")
 	debugCmd.Flags().Bool("dot", false, "Output GraphViz DOT format")
+	debugCmd.Flags().String("html", "", "Write an interactive HTML page of the dependency graph to this file")
 	debugCmd.Flags().Bool("flatten", false, "Output flattened/preprocessed template")
 	debugCmd.Flags().Bool("trace", false, "Trace path resolution for includes")
+	debugCmd.Flags().Int("depth", 0, "Maximum include depth to expand in the dependency tree (0 = unlimited)")
+	debugCmd.Flags().Bool("shaking", false, "Compile the template and show what tree-shaking kept and dropped per import")
 
 	// Bind flags to viper
 	_ = viper.BindPFlag("debug.path", debugCmd.Flags().Lookup("path"))
@@ -60,8 +71,11 @@ func init() {
 	_ = viper.BindPFlag("debug.refs", debugCmd.Flags().Lookup("refs"))
 	_ = viper.BindPFlag("debug.cycles", debugCmd.Flags().Lookup("cycles"))
 	_ = viper.BindPFlag("debug.dot", debugCmd.Flags().Lookup("dot"))
+	_ = viper.BindPFlag("debug.html", debugCmd.Flags().Lookup("html"))
 	_ = viper.BindPFlag("debug.flatten", debugCmd.Flags().Lookup("flatten"))
 	_ = viper.BindPFlag("debug.trace", debugCmd.Flags().Lookup("trace"))
+	_ = viper.BindPFlag("debug.depth", debugCmd.Flags().Lookup("depth"))
+	_ = viper.BindPFlag("debug.shaking", debugCmd.Flags().Lookup("shaking"))
 
 	// Set defaults
 	viper.SetDefault("debug.path", ".")
@@ -92,14 +106,14 @@ type DependencyGraph struct {
 	searchPaths  []string
 	extensions   map[string][]string // namespace prefixes to expand
 	traceResolve bool                // show path resolution
+	maxDepth     int                 // levels of includes to expand when printing the tree (0 = all)
 }
 
 var (
 	// Regex patterns for parsing
 	includePattern     = regexp.MustCompile(`\{\{#\s*include\s+"([^"]+)"(?:\s+"([^"]+)")*\s*#\}\}`)
-	namespacePattern   = regexp.MustCompile(`\{\{#\s*namespace\s+"([^"]+)"\s+"([^"]+)"(?:\s+"([^"]+)")*\s*#\}\}`)
+	namespacePattern   = regexp.MustCompile(`\{\{#\s*(?:namespace|include_ns)\s+"([^"]+)"\s+"([^"]+)"(?:\s+"([^"]+)")*\s*#\}\}`)
 	extendPattern      = regexp.MustCompile(`\{\{#\s*extend\s+"([^"]+)"\s+"([^"]+)"(?:\s+"([^"]+)"\s+"([^"]+)")*\s*#\}\}`)
-	definePattern      = regexp.MustCompile(`\{\{\s*define\s+"([^"]+)"`)
 	templateRefPattern = regexp.MustCompile(`\{\{\s*(?:template|block)\s+"([^"]+)"`)
 	// Pattern to strip comments (both HTML and Go template comments)
 	htmlCommentPattern = regexp.MustCompile(`<!--[\s\S]*?-->`)
@@ -118,8 +132,11 @@ func runDebug(cmd *cobra.Command, args []string) {
 	showRefs := viper.GetBool("debug.refs")
 	detectCycles := viper.GetBool("debug.cycles")
 	outputDot := viper.GetBool("debug.dot")
+	outputHTML := viper.GetString("debug.html")
 	flatten := viper.GetBool("debug.flatten")
 	traceResolve := viper.GetBool("debug.trace")
+	maxDepth := viper.GetInt("debug.depth")
+	showShaking := viper.GetBool("debug.shaking")
 
 	paths := strings.Split(searchPath, ",")
 
@@ -134,6 +151,7 @@ func runDebug(cmd *cobra.Command, args []string) {
 		searchPaths:  paths,
 		extensions:   make(map[string][]string),
 		traceResolve: traceResolve,
+		maxDepth:     maxDepth,
 	}
 
 	// Parse the root template and all dependencies
@@ -151,9 +169,18 @@ func runDebug(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if outputHTML != "" {
+		if err := graph.writeHTML(rootInfo.Path, outputHTML); err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %s\n", outputHTML)
+		return
+	}
+
 	// Print dependency tree
 	fmt.Println("=== Dependency Tree ===")
-	graph.printTree(templateFile, "", make(map[string]bool), verbose)
+	graph.printTree(templateFile, "", 0, make(map[string]bool), verbose)
 
 	// Show defines
 	if showDefines {
@@ -212,6 +239,12 @@ func runDebug(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Show tree-shaking
+	if showShaking {
+		fmt.Println("\n=== Tree Shaking ===")
+		printTreeShaking(templateFile, paths)
+	}
+
 	// Summary
 	fmt.Println("\n=== Summary ===")
 	fmt.Printf("Total templates analyzed: %d\n", len(graph.templates))
@@ -225,6 +258,36 @@ func runDebug(cmd *cobra.Command, args []string) {
 	fmt.Printf("Total references: %d\n", totalRefs)
 }
 
+// printTreeShaking compiles a template with the templar library and prints
+// the templates kept and dropped by each import with entry points
+func printTreeShaking(templateFile string, searchPaths []string) {
+	group := templar.NewTemplateGroup()
+	group.Loader = templar.NewFileSystemLoader(templar.LocalFolders(searchPaths...)...)
+	templates, err := group.Loader.Load(templateFile, "")
+	if err != nil {
+		fmt.Printf("ERROR loading template: %v\n", err)
+		return
+	}
+	if _, err := group.PreProcessHtmlTemplate(templates[0], nil); err != nil {
+		fmt.Printf("ERROR compiling template: %v\n", err)
+		return
+	}
+
+	shaken := templates[0].ShakenImports()
+	if len(shaken) == 0 {
+		fmt.Println("No imports with entry points.")
+	}
+	for _, imp := range shaken {
+		target := "include"
+		if imp.Namespace != "" {
+			target = "namespace " + imp.Namespace
+		}
+		fmt.Printf("%s (%s, entry points: %s)\n", imp.Path, target, strings.Join(imp.EntryPoints, ", "))
+		fmt.Printf("  kept:    %s\n", strings.Join(imp.Kept, ", "))
+		fmt.Printf("  dropped: %s\n", strings.Join(imp.Dropped, ", "))
+	}
+}
+
 // flattenTemplate uses the actual templar library to flatten a template
 func flattenTemplate(templateFile string, searchPaths []string, trace bool) {
 	// Create loader
@@ -501,10 +564,10 @@ func parseQuotedStrings(s string) []string {
 func (g *DependencyGraph) parseDefines(content string) []string {
 	var defines []string
 	seen := make(map[string]bool)
-	matches := definePattern.FindAllStringSubmatch(content, -1)
+	matches := templar.DefinePattern.FindAllStringSubmatch(content, -1)
 	for _, match := range matches {
-		name := match[1]
-		if !seen[name] {
+		name, err := strconv.Unquote(match[1])
+		if err == nil && !seen[name] {
 			defines = append(defines, name)
 			seen[name] = true
 		}
@@ -528,7 +591,7 @@ func (g *DependencyGraph) parseTemplateRefs(content string) []string {
 	return refs
 }
 
-func (g *DependencyGraph) printTree(path string, indent string, visited map[string]bool, verbose bool) {
+func (g *DependencyGraph) printTree(path string, indent string, depth int, visited map[string]bool, verbose bool) {
 	info, ok := g.templates[path]
 	if !ok {
 		fmt.Printf("%s%s (not analyzed)\n", indent, path)
@@ -537,6 +600,14 @@ func (g *DependencyGraph) printTree(path string, indent string, visited map[stri
 
 	// Show short path
 	shortPath := filepath.Base(path)
+	if g.maxDepth > 0 && depth > g.maxDepth {
+		if len(info.Directives) > 0 {
+			fmt.Printf("%s%s (not expanded)\n", indent, shortPath)
+		} else {
+			fmt.Printf("%s%s\n", indent, shortPath)
+		}
+		return
+	}
 	if visited[path] {
 		fmt.Printf("%s%s (already shown)\n", indent, shortPath)
 		return
@@ -555,7 +626,7 @@ func (g *DependencyGraph) printTree(path string, indent string, visited map[stri
 				fmt.Printf("%s  +- include \"%s\"\n", indent, d.File)
 			}
 			if depPath != "" {
-				g.printTree(depPath, indent+"  |  ", visited, verbose)
+				g.printTree(depPath, indent+"  |  ", depth+1, visited, verbose)
 			}
 
 		case "namespace":
@@ -566,7 +637,7 @@ func (g *DependencyGraph) printTree(path string, indent string, visited map[stri
 				fmt.Printf("%s  +- namespace \"%s\" \"%s\"\n", indent, d.Namespace, d.File)
 			}
 			if depPath != "" {
-				g.printTree(depPath, indent+"  |  ", visited, verbose)
+				g.printTree(depPath, indent+"  |  ", depth+1, visited, verbose)
 			}
 
 		case "extend":
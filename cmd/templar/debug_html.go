@@ -0,0 +1,165 @@
+package main
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// htmlGraphNode is a template file in the graph written by writeHTML.
+type htmlGraphNode struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Defines []string `json:"defines"`
+	Refs    []string `json:"refs"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// htmlGraphEdge is an include, namespace or extend relationship in the graph
+// written by writeHTML.  Extends (which apply within a file) and
+// unresolved files have no To.
+type htmlGraphEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Label     string `json:"label"`
+	Line      int    `json:"line"`
+}
+
+// writeHTML writes a self-contained HTML page for exploring the graph in a
+// browser: an expandable dependency tree starting at rootPath (a resolved
+// path, as the graph is keyed by), with the defines, references and
+// directives of the file selected in it.
+func (g *DependencyGraph) writeHTML(rootPath, outPath string) error {
+	var nodes []htmlGraphNode
+	var edges []htmlGraphEdge
+	for path, info := range g.templates {
+		node := htmlGraphNode{ID: path, Name: filepath.Base(path), Defines: info.Defines, Refs: info.TemplateRefs}
+		if info.Error != nil {
+			node.Error = info.Error.Error()
+		}
+		nodes = append(nodes, node)
+
+		for _, d := range info.Directives {
+			edge := htmlGraphEdge{From: path, Kind: d.Type, Namespace: d.Namespace, Line: d.Line}
+			switch d.Type {
+			case "include":
+				edge.To, _ = g.resolvePath(d.File, filepath.Dir(path))
+				edge.Label = "include " + d.File
+			case "namespace":
+				edge.To, _ = g.resolvePath(d.File, filepath.Dir(path))
+				edge.Label = "namespace " + d.Namespace + " " + d.File
+			case "extend":
+				edge.Label = "extend " + d.Args[0] + " → " + d.Args[1]
+			}
+			edges = append(edges, edge)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return graphPage.Execute(f, map[string]any{
+		"Title": filepath.Base(rootPath),
+		"Root":  rootPath,
+		"Nodes": nodes,
+		"Edges": edges,
+	})
+}
+
+// graphPage is the page written by writeHTML.  The graph is embedded as
+// JSON (escaped by html/template) and rendered by the inline script, so the
+// page works offline without GraphViz.
+var graphPage = template.Must(template.New("graph").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Template graph: {{ .Title }}</title>
+<style>
+  body { font-family: sans-serif; margin: 0; display: flex; height: 100vh; }
+  #tree, #details { overflow: auto; padding: 1em; }
+  #tree { flex: 1; border-right: 1px solid #ddd; }
+  #details { flex: 1; }
+  ul { list-style: none; padding-left: 1.2em; margin: 0; }
+  .node { cursor: pointer; }
+  .node:hover, .selected { background: #eef; }
+  .kind { font-size: 0.8em; color: #888; margin-right: 0.4em; }
+  .namespace { color: #a60; }
+  .extend { color: #06c; }
+  .error { color: #c00; }
+  .toggle { display: inline-block; width: 1em; }
+</style>
+</head>
+<body>
+<div id="tree"><h3>{{ .Title }}</h3></div>
+<div id="details"><p>Select a template to see its defines and references.</p></div>
+<script>
+const root = {{ .Root }};
+const nodes = {};
+for (const n of {{ .Nodes }} || []) nodes[n.id] = n;
+const edges = {{ .Edges }} || [];
+
+function el(tag, attrs, ...children) {
+  const e = document.createElement(tag);
+  Object.assign(e, attrs || {});
+  for (const c of children) e.append(c);
+  return e;
+}
+
+function outgoing(id) { return edges.filter(e => e.from === id); }
+
+let selected = null;
+function select(id, label) {
+  if (selected) selected.classList.remove("selected");
+  selected = label;
+  label.classList.add("selected");
+  const n = nodes[id] || {id: id, name: id, defines: [], refs: []};
+  const details = document.getElementById("details");
+  details.replaceChildren(el("h3", {textContent: n.name}), el("div", {textContent: n.id}));
+  if (n.error) details.append(el("p", {className: "error", textContent: n.error}));
+  const list = (title, items, render) => {
+    details.append(el("h4", {textContent: title + " (" + items.length + ")"}));
+    details.append(el("ul", {}, ...items.map(i => el("li", {}, render(i)))));
+  };
+  list("Defines", n.defines || [], d => d);
+  list("References", n.refs || [], r => r);
+  list("Directives", outgoing(id), e => "line " + e.line + ": " + e.label);
+  list("Used by", edges.filter(e => e.to === id), e => (nodes[e.from] || {}).name + " (" + e.kind + ")");
+}
+
+function treeItem(id, edge, ancestors) {
+  const n = nodes[id] || {name: id};
+  const children = outgoing(id);
+  const cycle = ancestors.has(id);
+  const toggle = el("span", {className: "toggle", textContent: children.length && !cycle ? "▸" : ""});
+  const label = el("span", {className: "node" + (n.error ? " error" : "")}, toggle);
+  if (edge) label.append(el("span", {className: "kind " + edge.kind, textContent: edge.namespace ? edge.namespace + ":" : edge.kind}));
+  label.append(n.name + (cycle ? " (cycle)" : ""));
+  const item = el("li", {}, label);
+  let sub = null;
+  label.onclick = () => {
+    select(id, label);
+    if (cycle || !children.length) return;
+    if (sub) {
+      sub.hidden = !sub.hidden;
+    } else {
+      const next = new Set(ancestors).add(id);
+      sub = el("ul", {}, ...children.map(e => e.to ? treeItem(e.to, e, next) :
+        el("li", {}, el("span", {className: "kind " + e.kind, textContent: e.label + (e.kind === "extend" ? "" : " (not found)")}))));
+      item.append(sub);
+    }
+    toggle.textContent = sub.hidden ? "▸" : "▾";
+  };
+  return item;
+}
+
+document.getElementById("tree").append(el("ul", {}, treeItem(root, null, new Set())));
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/panyam/templar"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffPathFlag  string
+	diffDataFlags []string
+	diffEntryFlag string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <template> [other-template]",
+	Short: "Show a unified diff of two renders of a template",
+	Long: `Render a template twice and show a unified diff of the outputs, to debug
+why a change altered the output.
+
+Either render one template with two data files (--data a.json --data b.json),
+or two templates (eg two versions of a page) with the same, optional, data file.
+Exits non-zero if the outputs differ.
+
+Examples:
+  # How does the page change between two data sets?
+  templar diff -p templates pages/home.html --data a.json --data b.json
+
+  # How does a new version of a template change its output?
+  templar diff -p templates pages/home.html pages/home.new.html --data a.json`,
+	Args:         cobra.RangeArgs(1, 2),
+	RunE:         runDiff,
+	SilenceUsage: true,
+}
+
+func init() {
+	diffCmd.Flags().StringVarP(&diffPathFlag, "path", "p", ".", "Comma-separated search paths for templates")
+	diffCmd.Flags().StringArrayVar(&diffDataFlags, "data", nil, "JSON data file to render with (give twice to compare two data sets)")
+	diffCmd.Flags().StringVarP(&diffEntryFlag, "entry", "e", "", "Template entry to render (default: the template's body)")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	var data []any
+	for _, file := range diffDataFlags {
+		d, err := loadJSONData(file)
+		if err != nil {
+			return err
+		}
+		data = append(data, d)
+	}
+
+	templateA, templateB := args[0], args[0]
+	var dataA, dataB any
+	switch {
+	case len(args) == 2 && len(data) <= 1:
+		templateB = args[1]
+		if len(data) == 1 {
+			dataA, dataB = data[0], data[0]
+		}
+	case len(args) == 1 && len(data) == 2:
+		dataA, dataB = data[0], data[1]
+	default:
+		return fmt.Errorf("give either one template and two --data files, or two templates and at most one --data file")
+	}
+
+	group := templar.NewTemplateGroup()
+	group.Loader = templar.NewFileSystemLoader(templar.LocalFolders(strings.Split(diffPathFlag, ",")...)...)
+
+	outA, err := renderForDiff(group, templateA, dataA)
+	if err != nil {
+		return err
+	}
+	outB, err := renderForDiff(group, templateB, dataB)
+	if err != nil {
+		return err
+	}
+
+	nameA, nameB := templateA, templateB
+	if len(args) == 1 {
+		nameA, nameB = diffDataFlags[0], diffDataFlags[1]
+	}
+	diff := templar.UnifiedDiff(nameA, nameB, outA, outB)
+	if diff == "" {
+		return nil
+	}
+	fmt.Print(diff)
+	return fmt.Errorf("outputs differ")
+}
+
+// renderForDiff renders a template (loaded by name) as html.
+func renderForDiff(group *templar.TemplateGroup, name string, data any) (string, error) {
+	templates, err := group.Loader.Load(name, "")
+	if err != nil {
+		return "", fmt.Errorf("loading %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := group.RenderHtmlTemplate(&buf, templates[0], diffEntryFlag, data, nil); err != nil {
+		return "", fmt.Errorf("rendering %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// loadJSONData reads a JSON file to render templates with.
+func loadJSONData(file string) (any, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var data any
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/panyam/templar"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fmtCheckFlag      bool
+	fmtExtensionsFlag string
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [path...]",
+	Short: "Normalize directive formatting in template files",
+	Long: `Rewrite {{# ... #}} directives in template files to a canonical style:
+single spaces between the delimiters, directive name and arguments, with
+arguments double-quoted.  The rest of the template is left untouched.
+
+Directories are searched recursively for files with the given extensions.
+Defaults to the current directory.
+
+Examples:
+  # Format all templates under the current directory
+  templar fmt
+
+  # Format specific files and directories
+  templar fmt templates/ shared/footer.html
+
+  # Exit with an error if any file needs formatting (for CI)
+  templar fmt --check templates/`,
+	RunE:         runFmt,
+	SilenceUsage: true,
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheckFlag, "check", false, "List files that need formatting and exit non-zero instead of rewriting them")
+	fmtCmd.Flags().StringVar(&fmtExtensionsFlag, "ext", "html,htm,tmpl", "Comma-separated template file extensions to format")
+
+	rootCmd.AddCommand(fmtCmd)
+}
+
+func runFmt(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	extensions := make(map[string]bool)
+	for _, ext := range strings.Split(fmtExtensionsFlag, ",") {
+		extensions["."+strings.TrimPrefix(strings.TrimSpace(ext), ".")] = true
+	}
+
+	var unformatted []string
+	for _, arg := range args {
+		err := filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Explicitly named files are formatted regardless of extension
+			if d.IsDir() || (path != arg && !extensions[filepath.Ext(path)]) {
+				return nil
+			}
+			changed, err := fmtFile(path, !fmtCheckFlag)
+			if err != nil {
+				return err
+			}
+			if changed {
+				unformatted = append(unformatted, path)
+				fmt.Println(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if fmtCheckFlag && len(unformatted) > 0 {
+		return fmt.Errorf("%d file(s) need formatting", len(unformatted))
+	}
+	return nil
+}
+
+// fmtFile formats the directives in a file, writing it back if write is set.
+// Returns whether the file's formatting changed.
+func fmtFile(path string, write bool) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	formatted := templar.FormatDirectives(string(content))
+	if formatted == string(content) {
+		return false, nil
+	}
+	if write {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(path, []byte(formatted), info.Mode().Perm()); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -12,8 +12,11 @@ import (
 var (
 	updateFlag  bool
 	verifyFlag  bool
+	frozenFlag  bool
+	hooksFlag   bool
 	dryRunFlag  bool
 	verboseFlag bool
+	checkFlag   bool
 )
 
 var getCmd = &cobra.Command{
@@ -34,6 +37,15 @@ Examples:
   # Verify local files match lock file
   templar get --verify
 
+  # Fetch exactly the locked commits, failing on any drift (for CI)
+  templar get --frozen
+
+  # Also run the post_fetch commands of sources (eg build steps)
+  templar get --allow-hooks
+
+  # Check that every fetched template compiles
+  templar get --check
+
   # Show what would be fetched without doing it
   templar get --dry-run`,
 	RunE: runGet,
@@ -42,6 +54,9 @@ Examples:
 func init() {
 	getCmd.Flags().BoolVarP(&updateFlag, "update", "u", false, "Update to latest versions matching refs")
 	getCmd.Flags().BoolVar(&verifyFlag, "verify", false, "Verify local files match lock file")
+	getCmd.Flags().BoolVar(&frozenFlag, "frozen", false, "Fetch exactly the commits in the lock file, failing if any source differs from or is missing in it")
+	getCmd.Flags().BoolVar(&hooksFlag, "allow-hooks", false, "Run the post_fetch commands of sources (executes arbitrary commands from templar.yaml)")
+	getCmd.Flags().BoolVar(&checkFlag, "check", false, "After fetching, compile every template of the fetched sources and fail if any do not compile")
 	getCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Show what would be fetched without doing it")
 	getCmd.Flags().BoolVarP(&verboseFlag, "verbose", "v", false, "Verbose output")
 
@@ -118,6 +133,17 @@ func runGet(cmd *cobra.Command, args []string) error {
 		return runVerify(config, configPath, sourcesToFetch)
 	}
 
+	// Frozen mode
+	if frozenFlag {
+		if updateFlag {
+			return fmt.Errorf("--frozen and --update cannot be used together")
+		}
+		if err := runFrozen(config, configPath, sourcesToFetch); err != nil {
+			return err
+		}
+		return runCheckSources(config, sourcesToFetch)
+	}
+
 	// Fetch sources
 	fmt.Printf("Fetching %d source(s)...\n", len(sourcesToFetch))
 
@@ -139,6 +165,9 @@ func runGet(cmd *cobra.Command, args []string) error {
 			commitDisplay = commitDisplay[:7]
 		}
 		fmt.Printf("OK (%s, %d files)\n", commitDisplay, result.FilesExtracted)
+		if err := runPostFetch(source, name, result.DestDir); err != nil {
+			return err
+		}
 	}
 
 	// Write vendor directory README
@@ -150,7 +179,7 @@ func runGet(cmd *cobra.Command, args []string) error {
 	// Write lock file
 	lockPath := filepath.Join(filepath.Dir(configPath), templar.DefaultLockFile)
 	lock := &templar.VendorLock{
-		Version: 1,
+		Version: templar.LockFileVersion,
 		Sources: make(map[string]templar.LockedSource),
 	}
 
@@ -175,6 +204,89 @@ func runGet(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("\nWrote %s\n", lockPath)
+	return runCheckSources(config, sourcesToFetch)
+}
+
+// runCheckSources compiles every template of the given sources if --check
+// was passed, printing the ones that fail.
+func runCheckSources(config *templar.VendorConfig, sources []string) error {
+	if !checkFlag {
+		return nil
+	}
+	config.SearchPaths = config.ResolveSearchPaths()
+	loader := templar.NewSourceLoader(config)
+
+	fmt.Printf("\nChecking %d source(s)...\n", len(sources))
+	failed := 0
+	for _, name := range sources {
+		failures, err := loader.CheckSource(name)
+		if err != nil {
+			return err
+		}
+		if len(failures) == 0 {
+			fmt.Printf("  %s: OK\n", name)
+			continue
+		}
+		fmt.Printf("  %s: %d template(s) failed\n", name, len(failures))
+		for _, f := range failures {
+			fmt.Printf("    FAIL %v\n", f)
+		}
+		failed += len(failures)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d vendored template(s) failed to compile", failed)
+	}
+	return nil
+}
+
+// runFrozen fetches the locked commit of each source without touching the
+// lock file, failing on the first source that drifted from the lock.
+func runFrozen(config *templar.VendorConfig, configPath string, sources []string) error {
+	lockPath := filepath.Join(filepath.Dir(configPath), templar.DefaultLockFile)
+
+	lock, err := templar.LoadLockFile(lockPath)
+	if err != nil {
+		return fmt.Errorf("--frozen requires a lock file: %w", err)
+	}
+
+	fmt.Printf("Fetching %d locked source(s)...\n", len(sources))
+	for _, name := range sources {
+		fmt.Printf("  %s: %s@%s... ", name, config.Sources[name].URL, lock.Sources[name].ResolvedCommit)
+		result, err := templar.FetchLockedSource(config, name, lock)
+		if err != nil {
+			fmt.Println("FAILED")
+			return err
+		}
+		fmt.Printf("OK (%d files)\n", result.FilesExtracted)
+		if err := runPostFetch(config.Sources[name], name, result.DestDir); err != nil {
+			return err
+		}
+	}
+
+	if err := templar.WriteVendorReadme(config.VendorDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write vendor README: %v\n", err)
+	}
+	return nil
+}
+
+// runPostFetch runs a fetched source's post_fetch command if hooks are
+// allowed, and warns that it was skipped otherwise.
+func runPostFetch(source templar.SourceConfig, name, dir string) error {
+	if source.PostFetch == "" {
+		return nil
+	}
+	if !hooksFlag {
+		fmt.Fprintf(os.Stderr, "Warning: skipped post_fetch for '%s' (%s); use --allow-hooks to run it\n", name, source.PostFetch)
+		return nil
+	}
+	fmt.Printf("    post_fetch: %s\n", source.PostFetch)
+	out, err := templar.RunPostFetch(source, dir)
+	if err != nil {
+		return fmt.Errorf("source '%s': %w", name, err)
+	}
+	if verboseFlag && out != "" {
+		fmt.Print(out)
+	}
 	return nil
 }
 
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"log"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/panyam/templar"
 	tu "github.com/panyam/templar/utils"
 )
 
@@ -12,6 +15,8 @@ var serveCmd = &cobra.Command{
 	Short: "Start an HTTP server to serve templates",
 	Long: `Start an HTTP server that serves Templar templates.
 
+Templates have access to the standard helper functions (dict, default, has, get).
+
 Config file options (serve section):
   serve:
     addr: ":8080"
@@ -21,21 +26,44 @@ Config file options (serve section):
     static:
       - /css:./styles
       - /js:./scripts
+    dev: false
+    access_log: true
+    verbose: false
+    cert: ./certs/server.crt
+    key: ./certs/server.key
 
 Examples:
   templar serve -t templates -s /static:./public
   templar serve --addr :8080 -t templates -t ../shared/templates
-  templar serve -t templates -s /css:./styles -s /js:./scripts`,
+  templar serve -t templates -s /css:./styles -s /js:./scripts
+  templar serve --dev -t templates   # Index of all templates at /
+  templar serve --cert server.crt --key server.key -t templates`,
 	Run: func(cmd *cobra.Command, args []string) {
 		addr := viper.GetString("serve.addr")
 		templateDirs := viper.GetStringSlice("serve.templates")
 		staticDirs := viper.GetStringSlice("serve.static")
+		dev := viper.GetBool("serve.dev")
+		accessLog := viper.GetBool("serve.access_log")
+		verbose := viper.GetBool("serve.verbose")
+		certFile := viper.GetString("serve.cert")
+		keyFile := viper.GetString("serve.key")
+		if (certFile == "") != (keyFile == "") {
+			log.Fatal("both --cert and --key must be provided to serve HTTPS")
+		}
 
 		b := tu.BasicServer{
 			TemplateDirs: templateDirs,
 			StaticDirs:   staticDirs,
+			FuncMaps:     []map[string]any{templar.StandardFuncs()},
+			Dev:          dev,
+			AccessLog:    accessLog,
+			Verbose:      verbose,
+			CertFile:     certFile,
+			KeyFile:      keyFile,
+		}
+		if err := b.Serve(nil, addr); err != nil {
+			log.Fatal("error starting server: ", err)
 		}
-		_ = b.Serve(nil, addr)
 	},
 }
 
@@ -43,11 +71,21 @@ func init() {
 	serveCmd.Flags().StringP("addr", "a", ":7777", "Address where the HTTP server will run")
 	serveCmd.Flags().StringArrayP("template", "t", nil, "Template directories to load templates from (can be repeated)")
 	serveCmd.Flags().StringArrayP("static", "s", nil, "Static directories in format <http_prefix>:<local_folder> (can be repeated)")
+	serveCmd.Flags().Bool("dev", false, "Enable development mode (serves an index of all templates at /)")
+	serveCmd.Flags().Bool("access-log", false, "Log every request with structured fields (method, path, status, duration, bytes)")
+	serveCmd.Flags().Bool("verbose", false, "Log the path and template (name and path only) served for every request")
+	serveCmd.Flags().String("cert", "", "TLS certificate file (enables HTTPS together with --key)")
+	serveCmd.Flags().String("key", "", "TLS private key file (enables HTTPS together with --cert)")
 
 	// Bind flags to viper
 	_ = viper.BindPFlag("serve.addr", serveCmd.Flags().Lookup("addr"))
 	_ = viper.BindPFlag("serve.templates", serveCmd.Flags().Lookup("template"))
 	_ = viper.BindPFlag("serve.static", serveCmd.Flags().Lookup("static"))
+	_ = viper.BindPFlag("serve.dev", serveCmd.Flags().Lookup("dev"))
+	_ = viper.BindPFlag("serve.access_log", serveCmd.Flags().Lookup("access-log"))
+	_ = viper.BindPFlag("serve.verbose", serveCmd.Flags().Lookup("verbose"))
+	_ = viper.BindPFlag("serve.cert", serveCmd.Flags().Lookup("cert"))
+	_ = viper.BindPFlag("serve.key", serveCmd.Flags().Lookup("key"))
 
 	// Set defaults
 	viper.SetDefault("serve.addr", ":7777")
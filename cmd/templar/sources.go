@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/panyam/templar"
@@ -15,14 +16,28 @@ var sourcesCmd = &cobra.Command{
 	Short: "List configured template sources",
 	Long: `List all external template sources defined in templar.yaml and their status.
 
+With --preview, render the index template a vendored source declares in its
+own templar.yaml (index: index.html) instead, to see a template pack's
+components before using them.
+
 Examples:
   # Show configured sources and their status
-  templar sources`,
+  templar sources
+
+  # Render the index page of the vendored uikit source
+  templar sources --preview @uikit > uikit.html`,
 	RunE: runSources,
 }
 
+var (
+	previewFlag     string
+	previewDataFlag string
+)
+
 func init() {
 	rootCmd.AddCommand(sourcesCmd)
+	sourcesCmd.Flags().StringVar(&previewFlag, "preview", "", "Render the index template of a vendored source (eg @uikit) to stdout")
+	sourcesCmd.Flags().StringVar(&previewDataFlag, "data", "", "JSON data file to render the --preview index with")
 }
 
 func runSources(cmd *cobra.Command, args []string) error {
@@ -45,6 +60,10 @@ func runSources(cmd *cobra.Command, args []string) error {
 	// Resolve paths relative to config file
 	config.VendorDir = config.ResolveVendorDir()
 
+	if previewFlag != "" {
+		return runPreviewSource(config, strings.TrimPrefix(previewFlag, "@"))
+	}
+
 	if len(config.Sources) == 0 {
 		fmt.Println("No sources configured in templar.yaml")
 		return nil
@@ -81,3 +100,18 @@ func runSources(cmd *cobra.Command, args []string) error {
 	_ = w.Flush()
 	return nil
 }
+
+// runPreviewSource renders the index template of the vendored source name
+// to stdout.
+func runPreviewSource(config *templar.VendorConfig, name string) error {
+	var data any
+	if previewDataFlag != "" {
+		var err error
+		if data, err = loadJSONData(previewDataFlag); err != nil {
+			return err
+		}
+	}
+	config.SearchPaths = config.ResolveSearchPaths()
+	loader := templar.NewSourceLoader(config)
+	return loader.PreviewSource(os.Stdout, name, data, nil)
+}
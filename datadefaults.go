@@ -0,0 +1,63 @@
+package templar
+
+import (
+	"fmt"
+	"maps"
+)
+
+// DefaultsMetaKey is the Metadata key holding the default data values of a
+// page, as a map[string]any (eg set from front matter by a custom loader,
+// with SetMeta, or with {{# default "Title" "Untitled" #}}).
+//
+// When a page is rendered with map[string]any (or nil) data, each default
+// whose key is missing from the data is added to a copy of it, so optional
+// fields render a sensible fallback instead of "<no value>" without the
+// handler having to know about them.  Keys the caller provided, even with a
+// nil value, are kept as is.  Other data (eg structs) is passed through
+// unchanged.
+const DefaultsMetaKey = "defaults"
+
+// defaultDirective implements the default directive, which records a default
+// data value in the template's Metadata (see DefaultsMetaKey):
+//
+//	{{# default "Title" "Untitled" #}}
+func (t *Template) defaultDirective(key string, value any) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("default requires a non-empty key")
+	}
+	// Copied so defaults set by the application are not modified
+	defaults, _ := t.Metadata[DefaultsMetaKey].(map[string]any)
+	defaults = maps.Clone(defaults)
+	if defaults == nil {
+		defaults = make(map[string]any)
+	}
+	defaults[key] = value
+	t.setDirectiveMeta(DefaultsMetaKey, defaults)
+	return fmt.Sprintf("{{/* Default: '%s' */}}", key), nil
+}
+
+// withDefaults returns data with root's defaults merged under it.
+func withDefaults(root *Template, data any) any {
+	defaults, _ := root.Metadata[DefaultsMetaKey].(map[string]any)
+	if len(defaults) == 0 {
+		return data
+	}
+	var out map[string]any
+	switch d := data.(type) {
+	case nil:
+		out = make(map[string]any, len(defaults))
+	case map[string]any:
+		out = maps.Clone(d)
+		if out == nil {
+			out = make(map[string]any, len(defaults))
+		}
+	default:
+		return data
+	}
+	for key, value := range defaults {
+		if _, ok := out[key]; !ok {
+			out[key] = value
+		}
+	}
+	return out
+}
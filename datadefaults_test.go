@@ -0,0 +1,55 @@
+package templar
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestDataDefaults verifies that defaults declared by a page fill in data the
+// caller did not provide, for the page and its layout.
+func TestDataDefaults(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":   `{{# default "Title" "Untitled" #}}{{# default "Count" 3 #}}{{ .Title }}/{{ .Count }}`,
+		"post.html":   `{{# meta "layout" "base.html" #}}{{# default "Title" "Post" #}}<p>{{ .Title }}</p>`,
+		"base.html":   `<title>{{ .Title }}</title>{{ .Content }}`,
+		"struct.html": `{{# default "Title" "Untitled" #}}{{ .Title }}`,
+		"meta.html":   `{{# default "Title" "Untitled" #}}{{ .Title }}-{{ .Subtitle }}`,
+	})
+
+	cases := []struct {
+		name string
+		data any
+		want string
+	}{
+		{"page.html", nil, "Untitled/3"},
+		{"page.html", map[string]any{"Title": "Home"}, "Home/3"},
+		{"page.html", map[string]any{"Title": "", "Count": 0}, "/0"},
+		{"post.html", nil, "<title>Post</title><p>Post</p>"},
+		{"post.html", map[string]any{"Title": "Hi"}, "<title>Hi</title><p>Hi</p>"},
+		{"struct.html", struct{ Title string }{"S"}, "S"},
+	}
+	for _, c := range cases {
+		if got := renderWith(t, group, c.name, c.data); got != c.want {
+			t.Errorf("%s with %v: got %q, want %q", c.name, c.data, got, c.want)
+		}
+	}
+
+	// Defaults set by the application (eg from front matter) are merged
+	// with the directives' and neither they nor the data are modified
+	templates, _ := group.Loader.Load("meta.html", "")
+	root := templates[0]
+	appDefaults := map[string]any{"Subtitle": "Sub"}
+	root.SetMeta(DefaultsMetaKey, appDefaults)
+	data := map[string]any{}
+	var buf bytes.Buffer
+	if err := group.RenderTextTemplate(&buf, root, "", data, nil); err != nil {
+		t.Fatalf("RenderTextTemplate failed: %v", err)
+	}
+	if got := buf.String(); got != "Untitled-Sub" {
+		t.Errorf("got %q, want %q", got, "Untitled-Sub")
+	}
+	if len(data) != 0 || len(appDefaults) != 1 {
+		t.Errorf("data or defaults were modified: %v, %v", data, appDefaults)
+	}
+}
@@ -0,0 +1,38 @@
+package templar
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefinePattern matches the start of a define or block action (including
+// trimmed ones like {{- define), capturing the template name as the quoted
+// string literal it is written as, ie "name" or `name`; use strconv.Unquote
+// to get the name.  It is shared by DefineLocation, duplicate define handling
+// and templar debug so they agree on what a define is.
+var DefinePattern = regexp.MustCompile(`\{\{-?\s*(?:define|block)\s+("(?:[^"\\]|\\.)*"|` + "`[^`]*`)")
+
+// defineName returns the name a DefinePattern match captured, or "" if its
+// literal is malformed.
+func defineName(literal string) string {
+	name, err := strconv.Unquote(literal)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// DefineLocation returns the 1-based line of the first {{ define "name" }}
+// (or {{ block "name" }}) in the template's RawSource, for "go to
+// definition" tooling and error messages.  ok is false if the template's own
+// source does not define name (defines in included files are not searched).
+func (t *Template) DefineLocation(name string) (line int, ok bool) {
+	source := string(t.RawSource)
+	for _, match := range DefinePattern.FindAllStringSubmatchIndex(source, -1) {
+		if defineName(source[match[2]:match[3]]) == name {
+			return strings.Count(source[:match[0]], "\n") + 1, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,62 @@
+package templar
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestDefineLocation verifies that defines and blocks are located by line.
+func TestDefineLocation(t *testing.T) {
+	tmpl := &Template{RawSource: []byte(`{{# include "base.html" #}}
+{{ define "header" }}<h1>{{ .Title }}</h1>{{ end }}
+
+{{- define "footer" -}}
+  {{ block "copyright" . }}(c){{ end }}
+{{- end }}
+{{ template "header" . }}`)}
+
+	for name, want := range map[string]int{"header": 2, "footer": 4, "copyright": 5} {
+		if line, ok := tmpl.DefineLocation(name); !ok || line != want {
+			t.Errorf("%s: got line %d, %v, want %d", name, line, ok, want)
+		}
+	}
+	if _, ok := tmpl.DefineLocation("base"); ok {
+		t.Error("expected no location for a template that is not defined")
+	}
+}
+
+// TestDuplicateDefineLines verifies that duplicate define errors point at
+// both defines.
+func TestDuplicateDefineLines(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": `{{# include "a.html" #}}{{# include "b.html" #}}{{ template "footer" . }}`,
+		"a.html":    "{{ define \"header\" }}{{ end }}\n{{ define \"footer\" }}A{{ end }}",
+		"b.html":    "\n\n{{ define \"footer\" }}B{{ end }}",
+	})
+	group.DuplicateDefines = DefineError
+	templates, _ := group.Loader.Load("page.html", "")
+	err := group.RenderHtmlTemplate(&bytes.Buffer{}, templates[0], "", nil, nil)
+	var dupErr *DuplicateDefineError
+	if !errors.As(err, &dupErr) || dupErr.FirstLine != 2 || dupErr.SecondLine != 3 {
+		t.Fatalf("expected lines 2 and 3, got %v", err)
+	}
+	if want := `template "footer" defined in both a.html:2 and b.html:3`; dupErr.Error() != want {
+		t.Errorf("got %q, want %q", dupErr.Error(), want)
+	}
+}
+
+// TestDefinePattern verifies that DefinePattern matches trimmed, block and
+// backquoted defines, and captures escaped names whole.
+func TestDefinePattern(t *testing.T) {
+	source := "{{ define \"a\" }}{{ end }}{{- define `b` -}}{{ end }}{{ block \"c\" . }}{{ end }}{{ define \"d\\\"e\" }}{{ end }}{{ template \"x\" }}"
+	var names []string
+	for _, match := range DefinePattern.FindAllStringSubmatch(source, -1) {
+		names = append(names, defineName(match[1]))
+	}
+	if want := []string{"a", "b", "c", `d"e`}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got %q, want %q", names, want)
+	}
+}
@@ -0,0 +1,63 @@
+package templar
+
+import (
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// htmlExtensions and textExtensions are the file extensions DetectHtml
+// treats as html and as plain text.  Others (eg .tmpl) are decided by the
+// template's content.
+var (
+	htmlExtensions = []string{".html", ".htm", ".xhtml", ".tmplus"}
+	textExtensions = []string{".txt", ".text", ".md", ".csv", ".json", ".yaml", ".yml", ".sql", ".sh"}
+)
+
+// htmlTagRegex matches an html start, end or self-closing tag (eg <div>,
+// </p>, <br/> or <a href="...">) or a doctype, but not comparisons like
+// "a < b" in plain text.
+var htmlTagRegex = regexp.MustCompile(`(?i)<!doctype\s+html|</?[a-z][a-z0-9-]*(\s[^<>]*)?/?>`)
+
+// actionRegex matches template actions, whose contents (eg a "<" in a
+// string literal) should not be mistaken for html.
+var actionRegex = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+
+// DetectHtml reports whether a template should be rendered as html:
+//
+//  1. if AsHtml is set, it is html,
+//  2. otherwise a Path with an html extension (.html, .htm, .xhtml, .tmplus)
+//     is html and one with a text extension (eg .txt, .md, .json) is not,
+//  3. otherwise (eg for .tmpl files or templates built from strings) it is
+//     html if its source, outside template actions, contains an html tag or
+//     doctype.
+func DetectHtml(t *Template) bool {
+	if t.AsHtml {
+		return true
+	}
+	ext := strings.ToLower(path.Ext(t.Path))
+	for _, htmlExt := range htmlExtensions {
+		if ext == htmlExt {
+			return true
+		}
+	}
+	for _, textExt := range textExtensions {
+		if ext == textExt {
+			return false
+		}
+	}
+	return htmlTagRegex.MatchString(actionRegex.ReplaceAllString(string(t.RawSource), ""))
+}
+
+// Render renders root as html if DetectHtml reports it is html, and as plain
+// text otherwise, so callers rendering templates of either kind (eg ones
+// created dynamically) don't have to pick between RenderHtmlTemplate and
+// RenderTextTemplate.  Call those directly to override the detection, eg to
+// render a text template that contains tags without escaping.
+func (t *TemplateGroup) Render(w io.Writer, root *Template, entry string, data any, funcs map[string]any) error {
+	if DetectHtml(root) {
+		return t.RenderHtmlTemplate(w, root, entry, data, funcs)
+	}
+	return t.RenderTextTemplate(w, root, entry, data, funcs)
+}
@@ -0,0 +1,51 @@
+package templar
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestDetectHtml verifies the html detection by AsHtml, extension and
+// content.
+func TestDetectHtml(t *testing.T) {
+	tests := []struct {
+		name   string
+		tmpl   *Template
+		isHtml bool
+	}{
+		{"as html", &Template{AsHtml: true, Path: "notes.txt"}, true},
+		{"html extension", &Template{Path: "page.html", RawSource: []byte("plain")}, true},
+		{"text extension", &Template{Path: "mail.txt", RawSource: []byte("<b>hi</b>")}, false},
+		{"tmpl with tags", &Template{Path: "page.tmpl", RawSource: []byte(`<div class="x">{{ .Name }}</div>`)}, true},
+		{"tmpl without tags", &Template{Path: "page.tmpl", RawSource: []byte("Hello {{ .Name }}")}, false},
+		{"doctype", &Template{RawSource: []byte("<!DOCTYPE html>\n{{ .Body }}")}, true},
+		{"self closing tag", &Template{RawSource: []byte("line<br/>line")}, true},
+		{"comparison", &Template{RawSource: []byte("if a < b and c > d")}, false},
+		{"tag in action", &Template{RawSource: []byte(`{{ print "<b>" }}`)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectHtml(tt.tmpl); got != tt.isHtml {
+				t.Errorf("DetectHtml() = %v, want %v", got, tt.isHtml)
+			}
+		})
+	}
+}
+
+// TestRender verifies that Render escapes html templates and not text ones.
+func TestRender(t *testing.T) {
+	group := NewTemplateGroup()
+	data := map[string]any{"Name": "<Ann>"}
+	for _, tt := range []struct{ source, want string }{
+		{"<p>{{ .Name }}</p>", "<p>&lt;Ann&gt;</p>"},
+		{"Hello {{ .Name }}", "Hello <Ann>"},
+	} {
+		var buf bytes.Buffer
+		if err := group.Render(&buf, &Template{RawSource: []byte(tt.source)}, "", data, nil); err != nil {
+			t.Fatalf("Render(%q) failed: %v", tt.source, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package templar
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// DiffRenders renders entry (or the root's body when empty) of root as html
+// once with dataA and once with dataB, and returns a unified diff of the two
+// outputs.  The diff is empty if the outputs are identical.  This is useful
+// for debugging which parts of a page a change in data (or, by rendering
+// two versions of a template and calling UnifiedDiff, in the template)
+// altered.
+func (t *TemplateGroup) DiffRenders(root *Template, entry string, dataA, dataB any) (string, error) {
+	var a, b bytes.Buffer
+	if err := t.RenderHtmlTemplate(&a, root, entry, dataA, nil); err != nil {
+		return "", err
+	}
+	if err := t.RenderHtmlTemplate(&b, root, entry, dataB, nil); err != nil {
+		return "", err
+	}
+	return UnifiedDiff("a", "b", a.String(), b.String()), nil
+}
+
+// UnifiedDiff returns a line based unified diff (as produced by diff -u)
+// turning a into b, labelled with nameA and nameB.  Returns an empty string
+// if a and b are identical.
+func UnifiedDiff(nameA, nameB, a, b string) string {
+	if a == b {
+		return ""
+	}
+	linesA, linesB := splitLines(a), splitLines(b)
+	ops := diffLines(linesA, linesB)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+	for start := 0; start < len(ops); {
+		// Find the next change and extend the hunk while changes are within
+		// 2*diffContext lines of each other
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		end := start
+		for i := start; i < len(ops) && i-end <= 2*diffContext; i++ {
+			if ops[i].kind != ' ' {
+				end = i
+			}
+		}
+		from, to := max(start-diffContext, 0), min(end+diffContext+1, len(ops))
+
+		hunk := ops[from:to]
+		lineA, lineB := hunk[0].lineA, hunk[0].lineB
+		countA, countB := 0, 0
+		for _, op := range hunk {
+			if op.kind != '+' {
+				countA++
+			}
+			if op.kind != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(lineA, countA), hunkRange(lineB, countB))
+		for _, op := range hunk {
+			fmt.Fprintf(&sb, "%c%s\n", op.kind, op.text)
+		}
+		start = to
+	}
+	return sb.String()
+}
+
+// diffOp is a single line of a diff: kept (' '), removed ('-') or added
+// ('+'), along with the 1-based line numbers it is at in a and b.
+type diffOp struct {
+	kind         byte
+	text         string
+	lineA, lineB int
+}
+
+// diffLines computes the line edits turning a into b from their longest
+// common subsequence.
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i + 1, j + 1})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i], i + 1, j + 1})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j], i + 1, j + 1})
+			j++
+		}
+	}
+	return ops
+}
+
+// hunkRange formats the start,count of a hunk header.  As with diff -u, an
+// empty range starts at the line before it.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits s into lines, without a trailing empty line for a final
+// newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,51 @@
+package templar
+
+import (
+	"testing"
+)
+
+// TestUnifiedDiff verifies hunks, context and line ranges of UnifiedDiff.
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\n3\nfour\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	want := `--- a
++++ b
+@@ -1,7 +1,7 @@
+ 1
+ 2
+ 3
+-4
++four
+ 5
+ 6
+ 7
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if got := UnifiedDiff("a", "b", a, b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got := UnifiedDiff("a", "b", a, a); got != "" {
+		t.Errorf("Expected no diff for identical inputs, got %q", got)
+	}
+}
+
+// TestDiffRenders verifies that DiffRenders diffs the output of two renders.
+func TestDiffRenders(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": "<h1>{{ .Title }}</h1>\n<p>Body</p>\n",
+	})
+	templates, _ := group.Loader.Load("page.html", "")
+	got, err := group.DiffRenders(templates[0], "", map[string]any{"Title": "Old"}, map[string]any{"Title": "New"})
+	if err != nil {
+		t.Fatalf("DiffRenders failed: %v", err)
+	}
+	want := "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-<h1>Old</h1>\n+<h1>New</h1>\n <p>Body</p>\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
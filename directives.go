@@ -0,0 +1,202 @@
+package templar
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Directive is a single {{# ... #}} preprocessor directive found in a
+// template's source, eg {{# include "header.html" #}}.
+type Directive struct {
+	// Name is the directive being invoked (include, namespace, extend, ...).
+	Name string
+
+	// Args are the directive's (unquoted) string arguments.
+	Args []string
+
+	// Start and End are the byte offsets of the directive, including its
+	// delimiters, in the source.
+	Start, End int
+
+	// Line is the 1-based line the directive starts on.
+	Line int
+
+	// TrimLeft and TrimRight record the "{{#-" and "-#}}" trim markers.
+	TrimLeft, TrimRight bool
+}
+
+// String returns the directive in canonical form: single spaces between the
+// delimiters, name and arguments, with arguments double-quoted.
+func (d Directive) String() string {
+	var sb strings.Builder
+	sb.WriteString("{{#")
+	if d.TrimLeft {
+		sb.WriteString("-")
+	}
+	sb.WriteString(" ")
+	sb.WriteString(d.Name)
+	for _, arg := range d.Args {
+		sb.WriteString(" ")
+		sb.WriteString(strconv.Quote(arg))
+	}
+	sb.WriteString(" ")
+	if d.TrimRight {
+		sb.WriteString("-")
+	}
+	sb.WriteString("#}}")
+	return sb.String()
+}
+
+// directiveRegex matches preprocessor directives ({{# ... #}}).
+var directiveRegex = regexp.MustCompile(`(?s)\{\{#.*?#\}\}`)
+
+// ParseDirectives returns the directives in source in the order they appear.
+// Only simple directives (a name followed by string arguments) are returned;
+// comments ({{#/* ... */#}}) and anything more complex are skipped, as is
+// everything between a verbatim and endverbatim directive.
+func ParseDirectives(source string) (directives []Directive) {
+	inVerbatim := false
+	for _, loc := range directiveRegex.FindAllStringIndex(source, -1) {
+		d, ok := parseDirective(source[loc[0]:loc[1]])
+		if !ok || (inVerbatim && d.Name != "endverbatim") {
+			continue
+		}
+		inVerbatim = d.Name == "verbatim"
+		d.Start, d.End = loc[0], loc[1]
+		d.Line = 1 + strings.Count(source[:loc[0]], "\n")
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+// parseDirective parses a single directive (including its delimiters).
+// Spacing between the name and arguments is optional, so sloppily written
+// directives like {{#include"a.html"#}} are still recognized.
+func parseDirective(text string) (d Directive, ok bool) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(text, "{{#"), "#}}")
+	if rest, found := strings.CutPrefix(inner, "- "); found {
+		d.TrimLeft, inner = true, rest
+	}
+	if rest, found := strings.CutSuffix(inner, " -"); found {
+		d.TrimRight, inner = true, rest
+	}
+	inner = strings.TrimSpace(inner)
+
+	end := strings.IndexFunc(inner, func(r rune) bool {
+		return !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	if end < 0 {
+		end = len(inner)
+	}
+	if end == 0 || unicode.IsDigit(rune(inner[0])) {
+		return d, false
+	}
+	d.Name, inner = inner[:end], inner[end:]
+
+	for inner = strings.TrimSpace(inner); inner != ""; inner = strings.TrimSpace(inner) {
+		quoted, err := strconv.QuotedPrefix(inner)
+		if err != nil || quoted[0] == '\'' {
+			return d, false
+		}
+		arg, _ := strconv.Unquote(quoted)
+		d.Args = append(d.Args, arg)
+		inner = inner[len(quoted):]
+	}
+	return d, true
+}
+
+// FormatDirectives rewrites every simple directive in source into its
+// canonical form (see Directive.String), leaving the rest of the source
+// untouched.
+func FormatDirectives(source string) string {
+	var sb strings.Builder
+	last := 0
+	for _, d := range ParseDirectives(source) {
+		sb.WriteString(source[last:d.Start])
+		sb.WriteString(d.String())
+		last = d.End
+	}
+	sb.WriteString(source[last:])
+	return sb.String()
+}
+
+// verbatimRegex matches a {{# verbatim #}}...{{# endverbatim #}} block,
+// capturing the opening tag's left trim marker, the content and the closing
+// tag's right trim marker.
+var verbatimRegex = regexp.MustCompile(`(?s)\{\{#(-?)\s*verbatim\s*-?#\}\}(.*?)\{\{#-?\s*endverbatim\s*(-?)#\}\}`)
+
+// expandVerbatim replaces each verbatim block in source with a directive
+// that emits its content literally, so neither directives nor template
+// actions in it are processed, eg to show templar syntax in documentation.
+func expandVerbatim(source string) string {
+	return verbatimRegex.ReplaceAllStringFunc(source, func(block string) string {
+		m := verbatimRegex.FindStringSubmatch(block)
+		// Escape # so the quoted content can't contain directive delimiters
+		quoted := strings.ReplaceAll(strconv.Quote(escapeActions(m[2])), "#", `\x23`)
+		trimLeft, trimRight := "", ""
+		if m[1] != "" {
+			trimLeft = "- "
+		}
+		if m[3] != "" {
+			trimRight = " -"
+		}
+		return "{{#" + trimLeft + quoted + trimRight + "#}}"
+	})
+}
+
+// escapeActions escapes template action delimiters in s so that it renders
+// literally when parsed as a template.
+func escapeActions(s string) string {
+	return strings.ReplaceAll(s, "{{", `{{"{{"}}`)
+}
+
+// UsesDirectives reports whether source contains any templar directive
+// ({{# ... #}}, including directive comments), ie whether it needs to be
+// preprocessed.  Files without directives are plain Go templates, eg for
+// migration tooling that reports how much of a project has adopted templar.
+// The check is a fast scan for "{{#" and does not parse the directives.
+func UsesDirectives(source []byte) bool {
+	return bytes.Contains(source, []byte("{{#"))
+}
+
+// DirectiveSummary summarizes the simple directives in a template's source
+// (see ParseDirectives).
+type DirectiveSummary struct {
+	// Counts is the number of directives of each name, eg {"include": 2}.
+	Counts map[string]int
+
+	// Files are the files referenced by include, namespace, include_ns and
+	// raw_include* directives, in the order they appear.
+	Files []string
+
+	// Namespaces are the namespaces imported by namespace and include_ns
+	// directives, in the order they appear.
+	Namespaces []string
+}
+
+// Total returns the number of directives summarized.
+func (s DirectiveSummary) Total() (total int) {
+	for _, n := range s.Counts {
+		total += n
+	}
+	return total
+}
+
+// SummarizeDirectives returns a summary of the directives in source.
+func SummarizeDirectives(source []byte) DirectiveSummary {
+	summary := DirectiveSummary{Counts: make(map[string]int)}
+	for _, d := range ParseDirectives(string(source)) {
+		summary.Counts[d.Name]++
+		switch {
+		case (d.Name == "include" || strings.HasPrefix(d.Name, "raw_include")) && len(d.Args) >= 1:
+			summary.Files = append(summary.Files, d.Args[0])
+		case (d.Name == "namespace" || d.Name == "include_ns") && len(d.Args) >= 2:
+			summary.Namespaces = append(summary.Namespaces, d.Args[0])
+			summary.Files = append(summary.Files, d.Args[1])
+		}
+	}
+	return summary
+}
@@ -0,0 +1,102 @@
+package templar
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// TestParseDirectives verifies that simple directives are parsed regardless
+// of spacing and quote style, and that comments are skipped.
+func TestParseDirectives(t *testing.T) {
+	source := "{{#namespace\"UI\"\"ui.html\"#}}\n<p>{{ .X }}</p>\n{{#/* a comment */#}}\n{{#-   include `a.html`   -#}}"
+	directives := ParseDirectives(source)
+	if len(directives) != 2 {
+		t.Fatalf("Expected 2 directives, got %+v", directives)
+	}
+	if d := directives[0]; d.Name != "namespace" || !reflect.DeepEqual(d.Args, []string{"UI", "ui.html"}) || d.Line != 1 {
+		t.Errorf("Unexpected first directive: %+v", d)
+	}
+	if d := directives[1]; d.Name != "include" || !reflect.DeepEqual(d.Args, []string{"a.html"}) || d.Line != 4 || !d.TrimLeft || !d.TrimRight {
+		t.Errorf("Unexpected second directive: %+v", d)
+	}
+}
+
+// TestFormatDirectives verifies that directives are rewritten to canonical
+// form without touching the rest of the template.
+func TestFormatDirectives(t *testing.T) {
+	source := "{{#namespace\"UI\"\"ui.html\"#}}\n{{ define \"x\" }}  {{#/* keep */#}}{{ end }}\n{{#-   include `a.html`   -#}}"
+	want := "{{# namespace \"UI\" \"ui.html\" #}}\n{{ define \"x\" }}  {{#/* keep */#}}{{ end }}\n{{#- include \"a.html\" -#}}"
+	if got := FormatDirectives(source); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := FormatDirectives(want); got != want {
+		t.Errorf("formatting is not idempotent: %q", got)
+	}
+}
+
+// TestVerbatim verifies that directives and actions inside a verbatim block
+// are emitted literally, in both html and text modes, and ignored by
+// ParseDirectives.
+func TestVerbatim(t *testing.T) {
+	source := "{{# include \"header.html\" #}}<pre>\n{{#- verbatim #}}{{# include \"x.html\" #}}\n{{ template \"header\" . }} {{/* c */}} \"#}}\"{{# endverbatim -#}}\n</pre>{{ template \"header\" }}"
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":   source,
+		"header.html": `{{ define "header" }}H{{ end }}`,
+	})
+	want := "<pre>{{# include \"x.html\" #}}\n{{ template \"header\" . }} {{/* c */}} \"#}}\"</pre>H"
+	if got := renderWith(t, group, "page.html", nil); got != want {
+		t.Errorf("html: got %q, want %q", got, want)
+	}
+
+	templates, _ := group.Loader.Load("page.html", "")
+	var buf bytes.Buffer
+	if err := group.RenderTextTemplate(&buf, templates[0], "", nil, nil); err != nil {
+		t.Fatalf("RenderTextTemplate failed: %v", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+
+	var names []string
+	for _, d := range ParseDirectives(source) {
+		names = append(names, d.Name)
+	}
+	if want := []string{"include", "verbatim", "endverbatim"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ParseDirectives got %v, want %v", names, want)
+	}
+}
+
+// TestSummarizeDirectives verifies that files are classified by whether they
+// use directives and that the directives they use are summarized.
+func TestSummarizeDirectives(t *testing.T) {
+	plain := []byte(`{{ define "page" }}<p>{{ .X }}</p>{{ end }}`)
+	if UsesDirectives(plain) {
+		t.Error("expected a plain Go template not to use directives")
+	}
+	if s := SummarizeDirectives(plain); s.Total() != 0 || len(s.Files) != 0 {
+		t.Errorf("expected an empty summary, got %+v", s)
+	}
+
+	source := []byte(`{{# include "a.html" #}}{{# include "b.html" "x" #}}{{# namespace "UI" "ui.html" #}}
+{{# include_ns "Icons" "icons.html" #}}{{# raw_include "logo.svg" #}}{{# extend "UI:page" "Page" #}}{{#/* note */#}}`)
+	if !UsesDirectives(source) {
+		t.Error("expected directives to be detected")
+	}
+	s := SummarizeDirectives(source)
+	if want := map[string]int{"include": 2, "namespace": 1, "include_ns": 1, "raw_include": 1, "extend": 1}; !reflect.DeepEqual(s.Counts, want) || s.Total() != 6 {
+		t.Errorf("counts = %v, want %v", s.Counts, want)
+	}
+	if want := []string{"a.html", "b.html", "ui.html", "icons.html", "logo.svg"}; !reflect.DeepEqual(s.Files, want) {
+		t.Errorf("files = %v, want %v", s.Files, want)
+	}
+	if want := []string{"UI", "Icons"}; !reflect.DeepEqual(s.Namespaces, want) {
+		t.Errorf("namespaces = %v, want %v", s.Namespaces, want)
+	}
+
+	// A comment alone still needs preprocessing (to be removed)
+	if !UsesDirectives([]byte(`{{#/* todo */#}}<p></p>`)) {
+		t.Error("expected a directive comment to be detected")
+	}
+}
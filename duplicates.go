@@ -0,0 +1,214 @@
+package templar
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template/parse"
+)
+
+// DuplicateDefinePolicy controls what happens when two different template
+// files define a template with the same name.
+type DuplicateDefinePolicy int
+
+const (
+	// DefineLastWins keeps the define from the file processed last.  This
+	// matches html/template and text/template's AddParseTree and is the
+	// default.
+	DefineLastWins DuplicateDefinePolicy = iota
+
+	// DefineError fails preprocessing with a *DuplicateDefineError naming
+	// both source files.
+	DefineError
+
+	// DefineFirstWins keeps the define from the file processed first and
+	// ignores later ones.
+	DefineFirstWins
+)
+
+// DuplicateDefineError is returned under the DefineError policy when a
+// template name is defined in more than one file.
+type DuplicateDefineError struct {
+	// Name is the name of the template defined more than once.
+	Name string
+
+	// First is the file that defined the template first.
+	First string
+
+	// Second is the file with the conflicting define.
+	Second string
+
+	// FirstLine and SecondLine are the lines of the defines in First and
+	// Second, or 0 if unknown.
+	FirstLine, SecondLine int
+}
+
+func (e *DuplicateDefineError) Error() string {
+	return fmt.Sprintf("template %q defined in both %s and %s", e.Name, fileLine(e.First, e.FirstLine), fileLine(e.Second, e.SecondLine))
+}
+
+// fileLine formats a file and a line in it (if known) as file:line.
+func fileLine(file string, line int) string {
+	if line > 0 {
+		return fmt.Sprintf("%s:%d", file, line)
+	}
+	return file
+}
+
+// defineTracker records which file each template name was defined in while
+// a root template's dependencies are parsed, and applies a
+// DuplicateDefinePolicy when two files define the same name.
+type defineTracker struct {
+	policy     DuplicateDefinePolicy
+	owners     map[string]string
+	ownerLines map[string]int
+	duplicates map[string]bool
+}
+
+func newDefineTracker(policy DuplicateDefinePolicy) *defineTracker {
+	return &defineTracker{
+		policy:     policy,
+		owners:     make(map[string]string),
+		ownerLines: make(map[string]int),
+		duplicates: make(map[string]bool),
+	}
+}
+
+// record notes the templates defined by curr (ignoring its directives and
+// includes), returning a *DuplicateDefineError under DefineError if an
+// earlier file already defined one of them.  It must be called for every
+// processed template, including ones flattened into their parent.
+//
+// Under DefineLastWins nothing is recorded, so renders do not pay for
+// parsing every file to list its defines; parse finds the duplicates it
+// needs in the flattened source instead.
+func (d *defineTracker) record(curr *Template) error {
+	if d.policy == DefineLastWins {
+		return nil
+	}
+	source := curr.sourceName()
+	for _, name := range fileDefines(curr) {
+		owner, exists := d.owners[name]
+		line, _ := curr.DefineLocation(name)
+		if !exists || owner == source {
+			d.owners[name] = source
+			d.ownerLines[name] = line
+			continue
+		}
+		if d.policy == DefineError {
+			return &DuplicateDefineError{Name: name, First: owner, Second: source, FirstLine: d.ownerLines[name], SecondLine: line}
+		}
+		d.duplicates[name] = true
+	}
+	return nil
+}
+
+// parse calls parseFn to parse curr into the compiled set, keeping only the
+// winning copy of each duplicate define seen so far.  Since includes are
+// flattened, parseFn is passed curr.ParsedSource with the losing copies
+// renamed.  lookup and add read and replace trees in the compiled set, and
+// restore defines from earlier parses under DefineFirstWins.
+func (d *defineTracker) parse(curr *Template, lookup func(string) *parse.Tree, add func(string, *parse.Tree) error, parseFn func(source string) error) error {
+	kept := make(map[string]*parse.Tree)
+	if d.policy == DefineFirstWins {
+		for name := range d.duplicates {
+			if tree := lookup(name); tree != nil {
+				kept[name] = tree
+			}
+		}
+	}
+
+	duplicates := d.duplicates
+	if d.policy == DefineLastWins && len(curr.includes) > 0 {
+		// Parsing a flattened source fails on a name defined twice, so
+		// rename all but the last copy of each
+		duplicates = repeatedDefines(curr.ParsedSource)
+	}
+	if err := parseFn(renameDuplicateDefines(curr.ParsedSource, duplicates, d.policy == DefineLastWins)); err != nil {
+		return err
+	}
+	for name, tree := range kept {
+		if err := add(name, tree); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileDefines returns the names of the templates defined in t's own source,
+// ignoring its directives and includes.
+func fileDefines(t *Template) []string {
+	return definedNames(t.sourceName(), directiveRegex.ReplaceAllString(string(t.RawSource), ""))
+}
+
+// definedNames returns the names of the non-empty templates defined in
+// contents, not including the top-level template itself.
+func definedNames(source, contents string) (names []string) {
+	tree := parse.New(source)
+	tree.Mode = parse.SkipFuncCheck
+	treeSet := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(contents, "", "", treeSet); err != nil {
+		// The real parse reports the error
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(treeSet)) {
+		if name != source && !parse.IsEmptyTree(treeSet[name].Root) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// repeatedDefines returns the names defined (or blocks) more than once in
+// source.
+func repeatedDefines(source string) map[string]bool {
+	counts := make(map[string]int)
+	repeated := make(map[string]bool)
+	for _, match := range DefinePattern.FindAllStringSubmatch(source, -1) {
+		name := defineName(match[1])
+		counts[name]++
+		if counts[name] > 1 {
+			repeated[name] = true
+		}
+	}
+	return repeated
+}
+
+// renameDuplicateDefines renames all but one define (or block) of each of the
+// given names in source: the last one if keepLast is set, otherwise the first.
+// The renamed copies are harmless templates that are never referenced.
+func renameDuplicateDefines(source string, names map[string]bool, keepLast bool) string {
+	if len(names) == 0 {
+		return source
+	}
+	matches := DefinePattern.FindAllStringSubmatchIndex(source, -1)
+	totals := make(map[string]int)
+	for _, match := range matches {
+		totals[defineName(source[match[2]:match[3]])]++
+	}
+
+	var out strings.Builder
+	counts := make(map[string]int)
+	last := 0
+	for _, match := range matches {
+		name := defineName(source[match[2]:match[3]])
+		if !names[name] {
+			continue
+		}
+		counts[name]++
+		keep := 1
+		if keepLast {
+			keep = totals[name]
+		}
+		if counts[name] == keep {
+			continue
+		}
+		out.WriteString(source[last:match[2]])
+		out.WriteString(strconv.Quote(fmt.Sprintf("_dup%d_%s", counts[name], name)))
+		last = match[3]
+	}
+	out.WriteString(source[last:])
+	return out.String()
+}
@@ -3,10 +3,10 @@ package templar
 import (
 	"embed"
 	"fmt"
-	"io"
-	"log"
+	"io/fs"
 	"log/slog"
-	"path/filepath"
+	"path"
+	"strings"
 )
 
 // EmbedFSLoader loads templates from the file system based on
@@ -34,31 +34,53 @@ func NewEmbedFSLoader(fss ...embed.FS) *EmbedFSLoader {
 // Load attempts to find and load a template with the given name.
 // If the name includes an extension, only files with that extension are considered.
 // Otherwise, files with any of the loader's recognized extensions are searched.
-// The cwd parameter is ignored as we can only provided templates from embedded FS
+// Relative names ("./x", "../x") are resolved against cwd, and other names are
+// looked up from the root of each embedded FS.  As with FileSystemLoader, the
+// Path of a loaded template is its slash-separated path within the FS, so
+// includes relative to it resolve against its directory.
 // Returns the loaded templates or TemplateNotFound if no matching templates were found.
-func (g *EmbedFSLoader) Load(name string, _ string) (template []*Template, err error) {
-	ext := filepath.Ext(name)
+func (g *EmbedFSLoader) Load(name string, cwd string) (template []*Template, err error) {
+	if strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../") {
+		name = path.Join(cwd, name)
+	}
+	ext := path.Ext(name)
 	extensions := g.Extensions
 	withoutext := name
 	if ext != "" {
 		extensions = []string{ext[1:]}
 		withoutext = name[:len(name)-len(ext)]
 	}
-	// log.Printf("Loading in CWD: %s, Name: %s, WithoutExt: %s, Ext: %s, Embeds: %v", cwd, name, withoutext, ext, folders)
 	for _, embedfs := range g.Embeds {
 		for _, ext := range extensions {
 			// check if folder/name.ext exists
-			withext := fmt.Sprintf("%s.%s", withoutext, ext)
-			f, err := embedfs.Open(withext)
-			if err != nil {
-				log.Println("Found error: ", withext, err)
-			} else {
-				// Found it so laod it
-				contents, err := io.ReadAll(f)
-				return []*Template{{RawSource: contents, Path: withext}}, err
+			withext := path.Clean(fmt.Sprintf("%s.%s", withoutext, ext))
+			contents, err := fs.ReadFile(embedfs, withext)
+			if err == nil {
+				return []*Template{{RawSource: contents, Path: withext}}, nil
 			}
 		}
 	}
-	slog.Warn("Template not found", "name", name)
+	slog.Warn("Template not found", "name", name, "cwd", cwd)
 	return nil, TemplateNotFound
 }
+
+// List returns the paths of all files in the embedded filesystems that have
+// one of the loader's Extensions.
+func (g *EmbedFSLoader) List() ([]string, error) {
+	seen := make(map[string]bool)
+	for _, embedfs := range g.Embeds {
+		err := fs.WalkDir(embedfs, ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && hasExtension(p, g.Extensions) {
+				seen[p] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sortedKeys(seen), nil
+}
@@ -0,0 +1,29 @@
+package templar
+
+import (
+	"embed"
+	"testing"
+)
+
+//go:embed testdata/embed
+var testEmbedFS embed.FS
+
+// TestEmbedFSLoader_RelativeIncludes verifies that templates loaded from an
+// embed.FS can include siblings relative to their own directory.
+func TestEmbedFSLoader_RelativeIncludes(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewEmbedFSLoader(testEmbedFS)
+
+	templates, err := group.Loader.Load("testdata/embed/pages/home", "")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got, want := templates[0].Path, "testdata/embed/pages/home.html"; got != want {
+		t.Errorf("Path = %q, want %q", got, want)
+	}
+
+	got := renderWith(t, group, "testdata/embed/pages/home.html", nil)
+	if want := "<h1>Home</h1>|<footer>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -1,15 +1,62 @@
 package templar
 
-import "os"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
 
 // panicOrError is a helper function that returns the given error
 // or panics if environment variables indicate panic behavior is desired.
 // This allows for configurable error handling throughout the package.
 func panicOrError(err error) error {
-	if err != nil {
+	if err != nil && !errors.Is(err, ErrStopWalk) {
 		if os.Getenv("PANIC_ON_ALL_ERRORS") == "true" || os.Getenv("PANIC_ON_TEMPLAR_ERRORS") == "true" {
 			panic(err)
 		}
 	}
 	return err
 }
+
+// TemplateError annotates an error with the template file (and line, when
+// known) that it originated from.
+type TemplateError struct {
+	// Path is the path of the template file the error occurred in.
+	Path string
+
+	// Line is the 1-based line number of the error, or 0 if unknown.
+	Line int
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *TemplateError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// errorLine extracts the line number from a text/template or html/template
+// error for a template with the given name (eg "template: name:12: ...").
+// Returns 0 if no line number is found.
+func errorLine(err error, name string) int {
+	msg := err.Error()
+	for _, prefix := range []string{"template: " + name + ":", "html/template:" + name + ":"} {
+		if rest, ok := strings.CutPrefix(msg, prefix); ok {
+			end := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+			if end > 0 {
+				line, _ := strconv.Atoi(rest[:end])
+				return line
+			}
+		}
+	}
+	return 0
+}
@@ -5,8 +5,10 @@ import (
 	"compress/gzip"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -15,6 +17,10 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// LockFileVersion is the newest lock file format this package reads and the
+// one it writes.
+const LockFileVersion = 1
+
 // VendorLock represents a lock file
 type VendorLock struct {
 	Version int                     `yaml:"version"`
@@ -42,7 +48,8 @@ type FetchResult struct {
 	FetchedAt      time.Time
 }
 
-// FetchSource fetches a single source from the config
+// FetchSource fetches a single source from the config into VendorDir/sourceName
+// using config.Fetcher (DefaultFetcher if nil).
 func FetchSource(config *VendorConfig, sourceName string) (*FetchResult, error) {
 	source, ok := config.Sources[sourceName]
 	if !ok {
@@ -62,24 +69,20 @@ func FetchSource(config *VendorConfig, sourceName string) (*FetchResult, error)
 		return nil, fmt.Errorf("failed to create destination: %w", err)
 	}
 
-	ref := source.GetRef()
-
-	// Fetch based on URL type
-	var commit string
-	var filesExtracted int
-	var err error
-
-	if isGitHubURL(source.URL) {
-		commit, filesExtracted, err = fetchFromGitHub(source, destDir, ref)
-	} else {
-		// Fallback to git clone for non-GitHub sources
-		commit, filesExtracted, err = fetchFromGit(source, destDir, ref)
+	fetcher := config.Fetcher
+	if fetcher == nil {
+		fetcher = DefaultFetcher{}
 	}
-
+	commit, err := fetcher.Fetch(source, destDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch source '%s': %w", sourceName, err)
 	}
 
+	filesExtracted, err := countFiles(destDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count fetched files: %w", err)
+	}
+
 	return &FetchResult{
 		SourceName:     sourceName,
 		URL:            source.URL,
@@ -92,6 +95,92 @@ func FetchSource(config *VendorConfig, sourceName string) (*FetchResult, error)
 	}, nil
 }
 
+// FetchLockedSource fetches a source at exactly the commit recorded for it in
+// lock, for reproducible builds (templar get --frozen).  It fails if the
+// source is missing from the lock, if its url, version or ref in the config
+// no longer match the lock, or if the fetched commit differs from the locked
+// one.
+func FetchLockedSource(config *VendorConfig, sourceName string, lock *VendorLock) (*FetchResult, error) {
+	source, ok := config.Sources[sourceName]
+	if !ok {
+		return nil, fmt.Errorf("source '%s' not found in config", sourceName)
+	}
+	locked, ok := lock.Sources[sourceName]
+	if !ok {
+		return nil, fmt.Errorf("source '%s' is not in the lock file", sourceName)
+	}
+	if locked.URL != source.URL || locked.Version != source.Version || locked.Ref != source.Ref {
+		return nil, fmt.Errorf("source '%s' changed since it was locked (%s@%s, locked %s@%s)",
+			sourceName, source.URL, source.GetRef(), locked.URL, (&SourceConfig{Version: locked.Version, Ref: locked.Ref}).GetRef())
+	}
+
+	pinned := source
+	pinned.Version, pinned.Ref = "", locked.ResolvedCommit
+	pinnedConfig := *config
+	pinnedConfig.Sources = map[string]SourceConfig{sourceName: pinned}
+	result, err := FetchSource(&pinnedConfig, sourceName)
+	if err != nil {
+		return nil, err
+	}
+	if result.ResolvedCommit != locked.ResolvedCommit {
+		return nil, fmt.Errorf("source '%s' resolved to %s, locked at %s", sourceName, result.ResolvedCommit, locked.ResolvedCommit)
+	}
+	result.Version, result.Ref = source.Version, source.Ref
+	return result, nil
+}
+
+// Fetcher downloads the files of a source into destDir, which exists and is
+// empty, and returns the commit (or ref) it resolved to.  Only the files
+// selected by the source's Path, Include and Exclude should be written.
+//
+// Set VendorConfig.Fetcher to replace the default, eg with a go-git based
+// fetcher (no git binary needed), an internal artifact store, or a fake in
+// tests.
+type Fetcher interface {
+	Fetch(source SourceConfig, destDir string) (commit string, err error)
+}
+
+// FetcherFunc adapts an ordinary function to a Fetcher.
+type FetcherFunc func(source SourceConfig, destDir string) (string, error)
+
+// Fetch calls f(source, destDir).
+func (f FetcherFunc) Fetch(source SourceConfig, destDir string) (string, error) {
+	return f(source, destDir)
+}
+
+// DefaultFetcher is the Fetcher used when VendorConfig.Fetcher is nil.  It
+// downloads GitHub sources as tarballs from GitHub's archive API and falls
+// back to git for other sources.
+type DefaultFetcher struct{}
+
+// Fetch fetches source at its configured ref into destDir.
+func (DefaultFetcher) Fetch(source SourceConfig, destDir string) (string, error) {
+	ref := source.GetRef()
+	var commit string
+	var err error
+	if isGitHubURL(source.URL) {
+		commit, _, err = fetchFromGitHub(source, destDir, ref)
+	} else {
+		commit, _, err = fetchFromGit(source, destDir, ref)
+	}
+	return commit, err
+}
+
+// countFiles returns the number of regular files under dir.
+func countFiles(dir string) (int, error) {
+	count := 0
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
 // isGitHubURL checks if the URL is a GitHub repository
 func isGitHubURL(url string) bool {
 	return strings.HasPrefix(url, "github.com/")
@@ -339,14 +428,148 @@ func matchesPatterns(path string, include, exclude []*regexp.Regexp) bool {
 	return true
 }
 
-// fetchFromGit fetches using git clone as fallback for non-GitHub sources
+// runner runs an external command and returns its combined output.  Tests
+// replace it to stub out git.
+var runner = func(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput() // #nosec G204 -- only invoked with git
+}
+
+// git runs a git command, including its output in the error if it fails.
+func git(args ...string) (string, error) {
+	out, err := runner("git", args...)
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// fetchFromGit fetches using git clone for non-GitHub sources.  The repo is
+// cloned into a temporary directory, ref is checked out (falling back to the
+// remote branch origin/ref) and the selected files are copied to destDir.
 func fetchFromGit(source SourceConfig, destDir, ref string) (string, int, error) {
-	// For non-GitHub sources, we still need to clone
-	// But we'll clone to a temp directory and copy only the needed files
+	cloneDir, err := os.MkdirTemp("", "templar-git-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create clone directory: %w", err)
+	}
+	defer os.RemoveAll(cloneDir)
 
-	// TODO: Implement git-based fetching with sparse checkout
-	// For now, return an error suggesting GitHub URLs
-	return "", 0, fmt.Errorf("non-GitHub sources not yet supported. Use github.com/owner/repo format")
+	if _, err := git("clone", "--quiet", "--no-checkout", gitURL(source.URL), cloneDir); err != nil {
+		return "", 0, err
+	}
+	if err := gitCheckout(cloneDir, ref); err != nil {
+		return "", 0, err
+	}
+	commit, err := git("-C", cloneDir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", 0, err
+	}
+
+	filesCopied, err := copyFiltered(filepath.Join(cloneDir, source.Path), destDir, source.Include, source.Exclude)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to copy files: %w", err)
+	}
+	return commit, filesCopied, nil
+}
+
+// commitSHARegex matches abbreviated and full commit hashes.
+var commitSHARegex = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// gitCheckout checks out ref in the repo cloned into dir.  Tags and local
+// branches are checked out directly, other branches from origin/ref.  Refs
+// that look like commit hashes never fall back to origin/ (there is no such
+// branch); a full hash that was not cloned (eg only reachable from a pull
+// request) is fetched from origin first.  Refs starting with "-" are rejected
+// so they are never read as options (eg from a hand edited lock file).
+func gitCheckout(dir, ref string) error {
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("ref '%s' must not start with '-'", ref)
+	}
+	_, err := git("-C", dir, "checkout", "--quiet", ref)
+	if err == nil {
+		return nil
+	}
+	if !commitSHARegex.MatchString(ref) {
+		if _, originErr := git("-C", dir, "checkout", "--quiet", "origin/"+ref); originErr == nil {
+			return nil
+		}
+		return fmt.Errorf("ref '%s' not found: %w", ref, err)
+	}
+	if len(ref) < 40 {
+		return fmt.Errorf("commit '%s' not found (abbreviated hashes must be reachable from a branch or tag): %w", ref, err)
+	}
+	if _, err := git("-C", dir, "fetch", "--quiet", "origin", ref); err != nil {
+		return fmt.Errorf("commit '%s' not found: %w", ref, err)
+	}
+	_, err = git("-C", dir, "checkout", "--quiet", ref)
+	return err
+}
+
+// RunPostFetch runs the source's PostFetch command with sh in dir (the
+// source's vendored directory).  The command's combined output is returned
+// and, if it fails, included in the error.  It does nothing if the source has
+// no PostFetch command.
+func RunPostFetch(source SourceConfig, dir string) (string, error) {
+	if source.PostFetch == "" {
+		return "", nil
+	}
+	// sh -c passes dir as $0 so it need not be quoted in the script
+	out, err := runner("sh", "-c", `cd "$0" && `+source.PostFetch, dir)
+	if err != nil {
+		return string(out), fmt.Errorf("post_fetch %q failed: %w\n%s", source.PostFetch, err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// gitURL turns a source URL into one git can clone, defaulting to https for
+// URLs without a scheme (eg gitlab.com/owner/repo).
+func gitURL(url string) string {
+	if strings.Contains(url, "://") || strings.HasPrefix(url, "git@") {
+		return url
+	}
+	return "https://" + url
+}
+
+// copyFiltered copies the files under srcDir (skipping .git) that match the
+// include/exclude patterns to destDir, preserving their relative paths.
+func copyFiltered(srcDir, destDir string, include, exclude []string) (int, error) {
+	includePatterns := compilePatterns(include)
+	excludePatterns := compilePatterns(exclude)
+	filesCopied := 0
+	err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		if !matchesPatterns(filepath.ToSlash(rel), includePatterns, excludePatterns) {
+			return nil
+		}
+		content, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(destDir, rel)
+		if err := os.MkdirAll(filepath.Dir(destPath), 0750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(destPath, content, 0600); err != nil {
+			return err
+		}
+		filesCopied++
+		return nil
+	})
+	return filesCopied, err
 }
 
 // WriteVendorReadme writes a README.md inside the vendor directory using templar's
@@ -458,6 +681,50 @@ func LoadLockFile(path string) (*VendorLock, error) {
 	if err := yaml.Unmarshal(data, &lock); err != nil {
 		return nil, fmt.Errorf("failed to parse lock file: %w", err)
 	}
+	if err := lock.upgrade(); err != nil {
+		return nil, err
+	}
 
 	return &lock, nil
 }
+
+// upgrade checks a loaded lock's version, migrating older formats to
+// LockFileVersion.  Lock files newer than this package understands are
+// rejected rather than misread.
+func (l *VendorLock) upgrade() error {
+	if l.Version > LockFileVersion {
+		return fmt.Errorf("lock file version %d requires a newer templar (this one supports up to version %d)", l.Version, LockFileVersion)
+	}
+	if l.Version < 0 {
+		return fmt.Errorf("invalid lock file version %d", l.Version)
+	}
+	// Migrate one version at a time as the format evolves
+	if l.Version == 0 {
+		// Written before versions were recorded; same format as version 1
+		l.Version = 1
+	}
+	if l.Sources == nil {
+		l.Sources = make(map[string]LockedSource)
+	}
+	return nil
+}
+
+// SourceVersionFuncs returns template funcs exposing the vendored sources in
+// lock to templates, eg to emit the exact template version in a meta tag
+// or as an asset version for cache busting:
+//
+//	group.AddFuncs(templar.SourceVersionFuncs(lock))
+//	<meta name="uikit-version" content="{{ sourceVersion "uikit" }}">
+//
+// sourceVersion returns the source's resolved commit, or an empty string if
+// the source is not in the lock (or lock is nil).
+func SourceVersionFuncs(lock *VendorLock) map[string]any {
+	return map[string]any{
+		"sourceVersion": func(name string) string {
+			if lock == nil {
+				return ""
+			}
+			return lock.Sources[name].ResolvedCommit
+		},
+	}
+}
@@ -277,6 +277,9 @@ func LoadLockFileFS(fsys WritableFS, name string) (*VendorLock, error) {
 	if err := yaml.Unmarshal(data, &lock); err != nil {
 		return nil, fmt.Errorf("failed to parse lock file: %w", err)
 	}
+	if err := lock.upgrade(); err != nil {
+		return nil, err
+	}
 	return &lock, nil
 }
 
@@ -1,10 +1,13 @@
 package templar
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"log/slog"
 	"path"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -21,7 +24,38 @@ type FileSystemLoader struct {
 	Folders []FSFolder
 
 	// Extensions is a list of file extensions to consider as templates.
+	// When a name is loaded without an extension, extensions are tried in
+	// this order (within each folder) and the first file found wins.
 	Extensions []string
+
+	// StrictExtensions makes loading a name without an extension fail with an
+	// *AmbiguousTemplateError if a folder has files for more than one of the
+	// Extensions (eg both home.html and home.tmpl), instead of silently
+	// picking by Extensions order.  Names with an extension are unaffected.
+	StrictExtensions bool
+
+	// Variant, if set, selects environment-specific versions of templates:
+	// loading page.html (or page) tries page.<Variant>.html before falling
+	// back to page.html, eg with Variant "dev" page.dev.html wins if it
+	// exists.  The variant is tried for each extension in turn, within each
+	// folder, so folder order takes precedence over variants (a base file in
+	// an earlier folder beats a variant in a later one).  A variant and its
+	// base file are never ambiguous under StrictExtensions.
+	Variant string
+}
+
+// AmbiguousTemplateError is returned by a FileSystemLoader with
+// StrictExtensions when a name without an extension matches several files.
+type AmbiguousTemplateError struct {
+	// Name is the name that was loaded.
+	Name string
+
+	// Paths are the matching files, in Extensions order.
+	Paths []string
+}
+
+func (e *AmbiguousTemplateError) Error() string {
+	return fmt.Sprintf("ambiguous template %q matches %s; specify an extension", e.Name, strings.Join(e.Paths, ", "))
 }
 
 // NewFileSystemLoader creates a loader that searches the given FS+path pairs.
@@ -69,19 +103,83 @@ func (g *FileSystemLoader) Load(name string, cwd string) (template []*Template,
 		if !g.folderExists(entry) {
 			continue
 		}
+		var found []*Template
 		for _, ext := range extensions {
-			withext := fmt.Sprintf("%s.%s", withoutext, ext)
-			contents, fullPath, err := g.readTemplate(entry, withext)
+			contents, fullPath, err := g.readVariant(entry, withoutext, ext)
 			if err != nil {
 				continue
 			}
-			return []*Template{{RawSource: contents, Path: fullPath}}, nil
+			found = append(found, &Template{RawSource: contents, Path: fullPath})
+			if !g.StrictExtensions {
+				break
+			}
+		}
+		if len(found) > 1 {
+			ambiguous := &AmbiguousTemplateError{Name: name}
+			for _, tmpl := range found {
+				ambiguous.Paths = append(ambiguous.Paths, tmpl.Path)
+			}
+			return nil, panicOrError(ambiguous)
+		}
+		if len(found) == 1 {
+			return found, nil
 		}
 	}
 	slog.Warn("Template not found", "name", name, "cwd", cwd)
 	return nil, TemplateNotFound
 }
 
+// List returns the names of all templates (files with one of the loader's
+// Extensions) found under each folder.  Names are relative to the folder
+// they were found in, so they can be passed straight back to Load.
+func (g *FileSystemLoader) List() ([]string, error) {
+	seen := make(map[string]bool)
+	for _, entry := range g.Folders {
+		if !g.folderExists(entry) {
+			continue
+		}
+		entry.resolve()
+		root := entry.Path
+		if root == "" {
+			root = "."
+		}
+		err := fs.WalkDir(entry.FS, root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !hasExtension(p, g.Extensions) {
+				return nil
+			}
+			name := p
+			if root != "." {
+				name = strings.TrimPrefix(p, root+"/")
+			}
+			seen[name] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sortedKeys(seen), nil
+}
+
+// hasExtension returns true if the name ends with one of the given extensions (without the leading dot).
+func hasExtension(name string, extensions []string) bool {
+	ext := path.Ext(name)
+	return ext != "" && slices.Contains(extensions, ext[1:])
+}
+
+// sortedKeys returns the keys of a set in sorted order.
+func sortedKeys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
 // resolve ensures FSFolder has an FS set — defaults to LocalFS if nil.
 func (entry *FSFolder) resolve() {
 	if entry.FS == nil {
@@ -119,6 +217,17 @@ func (g *FileSystemLoader) readTemplate(entry FSFolder, name string) ([]byte, st
 	return data, fpath, nil
 }
 
+// readVariant reads name.<Variant>.ext from an FSFolder if the loader has a
+// Variant and that file exists, and name.ext otherwise.
+func (g *FileSystemLoader) readVariant(entry FSFolder, name, ext string) ([]byte, string, error) {
+	if g.Variant != "" {
+		if data, fpath, err := g.readTemplate(entry, fmt.Sprintf("%s.%s.%s", name, g.Variant, ext)); err == nil {
+			return data, fpath, nil
+		}
+	}
+	return g.readTemplate(entry, fmt.Sprintf("%s.%s", name, ext))
+}
+
 // LoaderList is a composite loader that tries multiple loaders in sequence
 // and returns the first successful match.
 type LoaderList struct {
@@ -153,6 +262,34 @@ func (t *LoaderList) Load(name string, cwd string) (matched []*Template, err err
 	return nil, TemplateNotFound
 }
 
+// List aggregates the template names of all loaders (including the DefaultLoader)
+// that implement Lister.  Loaders that cannot enumerate their templates (including
+// wrappers returning ErrNotListable) are skipped.
+func (t *LoaderList) List() ([]string, error) {
+	seen := make(map[string]bool)
+	loaders := t.loaders
+	if t.DefaultLoader != nil {
+		loaders = append(append([]TemplateLoader{}, loaders...), t.DefaultLoader)
+	}
+	for _, loader := range loaders {
+		lister, ok := loader.(Lister)
+		if !ok {
+			continue
+		}
+		names, err := lister.List()
+		if errors.Is(err, ErrNotListable) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			seen[name] = true
+		}
+	}
+	return sortedKeys(seen), nil
+}
+
 // LocalFolders converts a list of directory paths to FSFolder entries.
 // Convenience for migrating code that passes string paths.
 func LocalFolders(dirs ...string) []FSFolder {
@@ -0,0 +1,163 @@
+package templar
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestFileSystemLoader_List verifies that List walks every folder, returns
+// only files with a recognized extension, and reports names relative to the
+// folder so they can be passed back to Load.
+func TestFileSystemLoader_List(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"templates/index.html":       "index",
+		"templates/pages/about.html": "about",
+		"templates/styles.css":       "body{}",
+		"shared/footer.tmpl":         "footer",
+	} {
+		full := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+
+	lfs := NewLocalFS(dir)
+	loader := NewFileSystemLoader(
+		FSFolder{FS: lfs, Path: "templates"},
+		FSFolder{FS: lfs, Path: "shared"},
+		FSFolder{FS: lfs, Path: "missing"},
+	)
+
+	names, err := loader.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	want := []string{"footer.tmpl", "index.html", "pages/about.html"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("List() = %v, want %v", names, want)
+	}
+
+	for _, name := range names {
+		if _, err := loader.Load(name, ""); err != nil {
+			t.Errorf("Listed name %q could not be loaded: %v", name, err)
+		}
+	}
+}
+
+// TestMapLoader_LoadAndList verifies that MapLoader resolves names with and
+// without extensions, resolves relative names against cwd, and lists its keys.
+func TestMapLoader_LoadAndList(t *testing.T) {
+	loader := NewMapLoader(map[string]string{
+		"pages/home.html":    "home",
+		"pages/partial.html": "partial",
+	})
+
+	tmpls, err := loader.Load("pages/home", "")
+	if err != nil || string(tmpls[0].RawSource) != "home" {
+		t.Fatalf("Expected pages/home to resolve via extensions, got %v, %v", tmpls, err)
+	}
+
+	tmpls, err = loader.Load("./partial.html", "pages")
+	if err != nil || tmpls[0].Path != "pages/partial.html" {
+		t.Fatalf("Expected relative name to resolve against cwd, got %v, %v", tmpls, err)
+	}
+
+	if _, err := loader.Load("nope.html", ""); err != TemplateNotFound {
+		t.Errorf("Expected TemplateNotFound, got %v", err)
+	}
+
+	names, _ := loader.List()
+	if !reflect.DeepEqual(names, []string{"pages/home.html", "pages/partial.html"}) {
+		t.Errorf("Unexpected names: %v", names)
+	}
+}
+
+// TestLoaderList_List verifies that LoaderList aggregates and de-duplicates
+// the names of all its listable loaders, including the DefaultLoader.
+func TestLoaderList_List(t *testing.T) {
+	list := (&LoaderList{}).
+		AddLoader(NewMapLoader(map[string]string{"a.html": "", "b.html": ""})).
+		AddLoader(NewMapLoader(map[string]string{"b.html": "", "c.html": ""}))
+	list.DefaultLoader = NewMapLoader(map[string]string{"d.html": ""})
+
+	names, err := list.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	want := []string{"a.html", "b.html", "c.html", "d.html"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("List() = %v, want %v", names, want)
+	}
+}
+
+// TestFileSystemLoader_StrictExtensions verifies that a name without an
+// extension resolves by Extensions order, unless StrictExtensions is set and
+// several extensions match.
+func TestFileSystemLoader_StrictExtensions(t *testing.T) {
+	mfs := NewMemFS()
+	mfs.SetFile("home.html", []byte("html"))
+	mfs.SetFile("home.tmpl", []byte("tmpl"))
+	mfs.SetFile("about.html", []byte("about"))
+	loader := NewFileSystemLoader(FSFolder{FS: mfs, Path: "."})
+
+	tmpls, err := loader.Load("home", "")
+	if err != nil || string(tmpls[0].RawSource) != "tmpl" {
+		t.Fatalf("Expected home.tmpl by Extensions order, got %v, %v", tmpls, err)
+	}
+
+	loader.StrictExtensions = true
+	var ambiguous *AmbiguousTemplateError
+	if _, err := loader.Load("home", ""); !errors.As(err, &ambiguous) || len(ambiguous.Paths) != 2 {
+		t.Errorf("Expected AmbiguousTemplateError, got %v", err)
+	}
+	if tmpls, err := loader.Load("home.html", ""); err != nil || string(tmpls[0].RawSource) != "html" {
+		t.Errorf("Expected explicit extension to resolve, got %v, %v", tmpls, err)
+	}
+	if _, err := loader.Load("about", ""); err != nil {
+		t.Errorf("Expected unambiguous name to resolve, got %v", err)
+	}
+}
+
+// TestFileSystemLoader_Variant verifies that a Variant file is preferred over
+// the base file, that loading falls back to the base file, and that folder
+// order takes precedence over variants.
+func TestFileSystemLoader_Variant(t *testing.T) {
+	app := NewMemFS()
+	app.SetFile("page.html", []byte("page"))
+	app.SetFile("page.dev.html", []byte("page dev"))
+	app.SetFile("footer.html", []byte("footer"))
+	shared := NewMemFS()
+	shared.SetFile("footer.dev.html", []byte("shared footer dev"))
+	shared.SetFile("nav.dev.tmpl", []byte("nav dev"))
+	loader := NewFileSystemLoader(FSFolder{FS: app, Path: "."}, FSFolder{FS: shared, Path: "."})
+	loader.Variant = "dev"
+	loader.StrictExtensions = true
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"page.html", "page dev"},
+		{"page", "page dev"},
+		{"footer.html", "footer"},
+		{"nav", "nav dev"},
+	}
+	for _, tt := range tests {
+		tmpls, err := loader.Load(tt.name, "")
+		if err != nil {
+			t.Errorf("Load(%q) failed: %v", tt.name, err)
+			continue
+		}
+		if got := string(tmpls[0].RawSource); got != tt.want {
+			t.Errorf("Load(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	loader.Variant = "prod"
+	if tmpls, err := loader.Load("page", ""); err != nil || string(tmpls[0].RawSource) != "page" {
+		t.Errorf("Expected fallback to page.html, got %v, %v", tmpls, err)
+	}
+}
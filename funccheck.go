@@ -0,0 +1,85 @@
+package templar
+
+import (
+	"fmt"
+	"text/template/parse"
+)
+
+// goBuiltinFuncs are the functions text/template and html/template
+// predefine for every template.
+var goBuiltinFuncs = []string{
+	"and", "call", "html", "index", "slice", "js", "len", "not", "or",
+	"print", "printf", "println", "urlquery",
+	"eq", "ge", "gt", "le", "lt", "ne",
+}
+
+// RequiredFuncs returns the names of the functions referenced by root (and
+// everything it includes) that are not defined: neither a Go template
+// builtin, a templar builtin, one of the group's Funcs, a func created by a
+// bindfunc directive nor, for namespaced templates, a func registered for
+// the namespace.  Call it before compiling to report a clear error instead
+// of Go's "function X not defined", eg:
+//
+//	if missing, _ := group.RequiredFuncs(root); len(missing) > 0 {
+//		return fmt.Errorf("template uses undefined function '%s'", missing[0])
+//	}
+//
+// Funcs passed to a single render are not known to the group, so they are
+// reported too.  The names are sorted.  Directives are executed (with
+// PreprocessData) to find the included templates.
+func (t *TemplateGroup) RequiredFuncs(root *Template) ([]string, error) {
+	known := make(map[string]bool)
+	for _, name := range goBuiltinFuncs {
+		known[name] = true
+	}
+	for name := range htmlBuiltins(nil) {
+		known[name] = true
+	}
+	for name := range t.Funcs {
+		known[name] = true
+	}
+
+	undefined := make(map[string]bool)
+	w := Walker{Loader: t.Loader, Data: t.PreprocessData, TrimDirectiveLines: t.TrimDirectiveLines, Bundles: t.bundles,
+		ProcessedTemplate: func(curr *Template) error {
+			for _, b := range curr.FuncBindings {
+				known[b.Name] = true
+			}
+			names, err := referencedFuncs(curr.sourceName(), curr.ParsedSource)
+			if err != nil {
+				return err
+			}
+			nsFuncs := t.namespaceFuncs[curr.Namespace]
+			for _, name := range names {
+				if _, ok := nsFuncs[name]; !ok && !known[name] {
+					undefined[name] = true
+				}
+			}
+			return nil
+		},
+	}
+	if err := w.Walk(root); err != nil {
+		return nil, err
+	}
+	return sortedKeys(undefined), nil
+}
+
+// referencedFuncs returns the names of the functions called in source,
+// without checking that they are defined.
+func referencedFuncs(name, source string) ([]string, error) {
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	treeSet := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(source, "", "", treeSet); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	var names []string
+	for _, tree := range treeSet {
+		WalkNodes(tree.Root, func(node parse.Node) {
+			if ident, ok := node.(*parse.IdentifierNode); ok {
+				names = append(names, ident.Ident)
+			}
+		})
+	}
+	return names, nil
+}
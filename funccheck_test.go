@@ -0,0 +1,36 @@
+package templar
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestRequiredFuncs verifies that RequiredFuncs reports the functions used
+// by a template and its includes that are not defined.
+func TestRequiredFuncs(t *testing.T) {
+	group := NewTemplateGroup()
+	group.AddFuncs(map[string]any{"upper": func(s string) string { return s }})
+	group.RegisterNamespaceFuncs("UI", map[string]any{"icon": func() string { return "" }})
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": `{{# include "header.html" #}}{{# namespace "UI" "widgets.html" #}}` +
+			`{{ define "page" }}{{ upper .Title | printf "%s" }}{{ if fmtDate .When }}{{ tryTemplate "x" . }}{{ end }}{{ end }}`,
+		"header.html":  `{{ define "header" }}{{ len .Items }}{{ money .Price }}{{ end }}`,
+		"widgets.html": `{{ define "button" }}{{ icon }}{{ t "ok" }}{{ end }}{{ define "card" }}{{ fmtDate .When }}{{ end }}`,
+		"other.html":   `{{ define "other" }}{{ icon }}{{ end }}`,
+	})
+	root := group.MustLoad("page.html", "")[0]
+
+	missing, err := group.RequiredFuncs(root)
+	if err != nil {
+		t.Fatalf("RequiredFuncs failed: %v", err)
+	}
+	if want := []string{"fmtDate", "money"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("got %v, want %v", missing, want)
+	}
+
+	// Namespace funcs are only defined within their namespace
+	missing, err = group.RequiredFuncs(group.MustLoad("other.html", "")[0])
+	if err != nil || !reflect.DeepEqual(missing, []string{"icon"}) {
+		t.Errorf("got %v, %v; want [icon]", missing, err)
+	}
+}
@@ -0,0 +1,87 @@
+package templar
+
+import (
+	htmpl "html/template"
+	"reflect"
+
+	gotl "github.com/panyam/goutils/template"
+)
+
+// StandardFuncs returns a set of general purpose helper functions that
+// templates commonly rely on:
+//
+//   - dict: builds a map from alternating key/value arguments, eg
+//     {{ template "button" dict "Text" "Save" "Class" "primary" }}
+//   - default: returns a fallback when a value is empty, eg
+//     {{ default "Untitled" .Title }}
+//   - has: reports whether a dotted path resolves to a non-nil value in maps
+//     or structs, eg {{ if has "User.Address.City" . }}
+//   - get: returns the value at a dotted path, or an optional default when it
+//     is missing or nil, eg {{ get "User.Name" . "Anonymous" }}
+//
+// A fresh map is returned on each call so callers can freely add to it.
+func StandardFuncs() map[string]any {
+	return map[string]any{
+		"dict":    gotl.ValuesToDict,
+		"default": defaultValue,
+		"has":     hasPath,
+		"get":     getPath,
+	}
+}
+
+// getPath returns the value at the dotted path in data, or the first of def
+// (nil if none) if the path is missing or nil.
+func getPath(path string, data any, def ...any) any {
+	if v, ok := lookupPath(data, path); ok && !isNilValue(v) {
+		return v.Interface()
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return nil
+}
+
+// defaultValue returns def if value is empty (nil, zero, or an empty
+// string/slice/map), otherwise value.
+func defaultValue(def any, value any) any {
+	if isEmptyValue(value) {
+		return def
+	}
+	return value
+}
+
+// isEmptyValue reports whether v is nil or the zero value for its type.
+// Strings, slices, maps, arrays and channels are empty if they have no elements.
+func isEmptyValue(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array, reflect.Chan:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	}
+	return rv.IsZero()
+}
+
+// SafeData wraps every value of fields as template.HTML so html/template
+// inserts them verbatim instead of escaping them.  Use it for fragments your
+// handler has already sanitized, and merge the result into your data map:
+//
+//	data := map[string]any{"User": user}
+//	maps.Copy(data, templar.SafeData(map[string]string{"Bio": sanitizedBio}))
+//
+// WARNING: this bypasses html/template's escaping entirely.  Any value that
+// contains user input which was not sanitized (eg with an HTML sanitizer such
+// as bluemonday) is an XSS vulnerability.  Only pass values you would be
+// comfortable writing directly into the page, and never bulk-wrap a whole
+// request or database record.
+func SafeData(fields map[string]string) map[string]htmpl.HTML {
+	out := make(map[string]htmpl.HTML, len(fields))
+	for key, value := range fields {
+		out[key] = htmpl.HTML(value)
+	}
+	return out
+}
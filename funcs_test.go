@@ -0,0 +1,74 @@
+package templar
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestStandardFuncs verifies that dict and default are usable from templates
+// once StandardFuncs is added to a group.
+func TestStandardFuncs(t *testing.T) {
+	group := NewTemplateGroup().AddFuncs(StandardFuncs())
+	root := &Template{
+		Name: "page",
+		RawSource: []byte(`{{ define "greet" }}Hello {{ .Name }}{{ end }}` +
+			`{{ template "greet" dict "Name" "World" }}|{{ default "Untitled" .Title }}|{{ default "x" .Count }}`),
+	}
+
+	var buf bytes.Buffer
+	if err := group.RenderHtmlTemplate(&buf, root, "", map[string]any{"Title": "", "Count": 3}, nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got, want := buf.String(), "Hello World|Untitled|3"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestStandardFuncs_HasGet verifies safe nested lookups through maps, structs
+// and pointers with has and get.
+func TestStandardFuncs_HasGet(t *testing.T) {
+	type Address struct{ City string }
+	type User struct {
+		Name    string
+		Address *Address
+	}
+	group := NewTemplateGroup().AddFuncs(StandardFuncs())
+	root := &Template{
+		Name: "page",
+		RawSource: []byte(`{{ has "User.Name" . }},{{ has "User.Address.City" . }},{{ has "Missing" . }}|` +
+			`{{ get "User.Name" . }},{{ get "User.Address.City" . "Nowhere" }},{{ get "Meta.Lang" . "en" }}`),
+	}
+	data := map[string]any{
+		"User": &User{Name: "Ann"},
+		"Meta": map[string]string{},
+	}
+
+	var buf bytes.Buffer
+	if err := group.RenderHtmlTemplate(&buf, root, "", data, nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got, want := buf.String(), "true,false,false|Ann,Nowhere,en"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestSafeData verifies that only values wrapped by SafeData skip escaping.
+// Note the script tag passing through: SafeData must only be given
+// sanitized HTML, since anything it wraps is trusted completely.
+func TestSafeData(t *testing.T) {
+	group := NewTemplateGroup()
+	root := &Template{RawSource: []byte(`{{ .Bio }}|{{ .Name }}`)}
+
+	data := map[string]any{"Name": "<b>Bob</b>"}
+	for key, value := range SafeData(map[string]string{"Bio": "<em>Hi</em><script>x()</script>"}) {
+		data[key] = value
+	}
+
+	var buf bytes.Buffer
+	if err := group.RenderHtmlTemplate(&buf, root, "", data, nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got, want := buf.String(), "<em>Hi</em><script>x()</script>|&lt;b&gt;Bob&lt;/b&gt;"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -7,8 +7,12 @@ import (
 	"log/slog"
 	"maps"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	ttmpl "text/template"
 	"text/template/parse"
+	"time"
 )
 
 // TemplateGroup manages a collection of templates and their dependencies,
@@ -23,19 +27,96 @@ type TemplateGroup struct {
 	// Loader is used to resolve and load template dependencies.
 	Loader TemplateLoader
 
+	// Translator, if set, backs the "t" template function used for translations.
+	Translator Translator
+
+	// PreprocessData is the data directives are executed with, so a
+	// template can choose directives at compile time, eg:
+	//
+	//	{{# if .UseNewLayout #}}{{# extend "Base:v2" ... #}}{{# else #}}{{# extend "Base:v1" ... #}}{{# end #}}
+	PreprocessData map[string]any
+
+	// DataMiddleware transforms the data passed to every render before the
+	// template is executed, eg to add a CSRF token or computed fields.  Each
+	// func receives the entry being rendered (empty for the root's body) and
+	// the output of the previous one.  An error aborts the render.
+	DataMiddleware []func(entry string, data any) (any, error)
+
+	// DefaultEntry names the template rendered when neither an entry nor the
+	// root's Name is given (eg "page"), if the compiled set defines it.
+	// Otherwise the root template's body is rendered.
+	DefaultEntry string
+
+	// TrimDirectiveLines removes lines containing only directives (eg an
+	// include on its own line) so they don't render as blank lines.
+	TrimDirectiveLines bool
+
+	// DuplicateDefines controls what happens when two files define a template
+	// with the same name.  Defaults to DefineLastWins.
+	DuplicateDefines DuplicateDefinePolicy
+
+	// StrictGlobalRefs fails preprocessing with a *ShadowedGlobalError when a
+	// namespaced file references a global template with "::" (eg
+	// "::formatDate") while defining a template of the same name itself.
+	// Otherwise such references are only logged as warnings.
+	StrictGlobalRefs bool
+
+	// MissingKeys controls what rendering does when data is a map without a
+	// key a template uses (eg a typo in .FieldName).  Defaults to
+	// MissingKeyDefault.
+	MissingKeys MissingKeyMode
+
 	htmlTemplates map[string]*htmpl.Template
 	textTemplates map[string]*ttmpl.Template
 	dependencies  map[string]map[string]bool
+
+	// namespaceFuncs holds functions only available to templates imported
+	// under a given namespace (see RegisterNamespaceFuncs).
+	namespaceFuncs map[string]map[string]any
+
+	// ScopedClassPrefix, if set, returns a prefix added to the static CSS
+	// class names in the templates of a namespace (eg "ui-" for UI), so
+	// imported components are style-isolated from the host page like CSS
+	// modules.  The namespace's stylesheet must use the prefixed names.
+	// Return "" to leave a namespace unscoped.
+	ScopedClassPrefix func(namespace string) string
+
+	// bundles holds the files included by each bundle directive (see
+	// RegisterBundle).
+	bundles map[string][]string
+
+	// Observer, if set, is notified of loads, renders and cache hits/misses
+	// for metrics.  Nil by default.
+	Observer Observer
+
+	// OnRenderError, if set, is called whenever a Render*Template call
+	// fails, with the entry rendered (or the root's path if no entry was
+	// given), eg to report render errors to an error tracker in one place
+	// rather than in every handler.  The error is still returned to the
+	// caller.  It may be called concurrently from concurrent renders.
+	OnRenderError func(name string, err error)
+
+	// mu guards the caches below, which may be used from concurrent renders
+	mu          sync.Mutex
+	renderCache map[string]cachedRender
+
+	// namespaceCache holds the rewritten trees of namespaced imports, keyed
+	// by namespaceCacheKey
+	namespaceCache map[string]*cachedNamespace
 }
 
 // NewTemplateGroup creates a new empty template group with initialized internals.
 func NewTemplateGroup() *TemplateGroup {
 	return &TemplateGroup{
-		Funcs:         make(map[string]any),
-		htmlTemplates: make(map[string]*htmpl.Template),
-		textTemplates: make(map[string]*ttmpl.Template),
-		templates:     make(map[string]*Template),
-		dependencies:  make(map[string]map[string]bool),
+		Funcs:          make(map[string]any),
+		htmlTemplates:  make(map[string]*htmpl.Template),
+		textTemplates:  make(map[string]*ttmpl.Template),
+		templates:      make(map[string]*Template),
+		dependencies:   make(map[string]map[string]bool),
+		namespaceFuncs: make(map[string]map[string]any),
+		bundles:        make(map[string][]string),
+		renderCache:    make(map[string]cachedRender),
+		namespaceCache: make(map[string]*cachedNamespace),
 	}
 }
 
@@ -57,11 +138,169 @@ func (t *TemplateGroup) AddFuncs(funcs map[string]any) *TemplateGroup {
 	return t
 }
 
+// RegisterNamespaceFuncs makes funcs available only to templates imported under
+// the given namespace (via the namespace directive).  This lets third-party
+// component libraries bring their own helpers without adding them to the
+// group's global Funcs.  Within the namespace these funcs shadow global funcs
+// with the same name.  Returns the template group for method chaining.
+func (t *TemplateGroup) RegisterNamespaceFuncs(namespace string, funcs map[string]any) *TemplateGroup {
+	if t.namespaceFuncs[namespace] == nil {
+		t.namespaceFuncs[namespace] = make(map[string]any)
+	}
+	maps.Copy(t.namespaceFuncs[namespace], funcs)
+	t.clearNamespaceCache()
+	return t
+}
+
+// namespacedFuncName returns the name a namespace-scoped func is registered
+// under in the compiled template.  It must be a valid identifier and must
+// not collide with user funcs.
+func namespacedFuncName(namespace, name string) string {
+	mangled := []byte("_ns_")
+	for i := 0; i < len(namespace); i++ {
+		c := namespace[i]
+		if c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			mangled = append(mangled, c)
+		} else {
+			mangled = append(mangled, '_')
+		}
+	}
+	return string(mangled) + "__" + name
+}
+
+// RegisterBundle registers a named list of files that a template can include
+// together with {{# bundle "name" #}}, eg a set of form components used by
+// many pages.  Registering a bundle again replaces its files.
+// Returns the template group for method chaining.
+func (t *TemplateGroup) RegisterBundle(name string, files []string) *TemplateGroup {
+	t.bundles[name] = slices.Clone(files)
+	t.clearCaches()
+	return t
+}
+
+// Clone returns an independent copy of this group.  Funcs and internal caches
+// are copied so changes to the clone do not affect the original.  A LoaderList
+// is copied so loaders can be added to the clone without affecting the
+// original, while other loaders are shared.
+func (t *TemplateGroup) Clone() *TemplateGroup {
+	out := NewTemplateGroup()
+	maps.Copy(out.Funcs, t.Funcs)
+	t.cloneCache(out)
+	maps.Copy(out.templates, t.templates)
+	for name, deps := range t.dependencies {
+		out.dependencies[name] = maps.Clone(deps)
+	}
+	for ns, funcs := range t.namespaceFuncs {
+		out.namespaceFuncs[ns] = maps.Clone(funcs)
+	}
+	for name, files := range t.bundles {
+		out.bundles[name] = slices.Clone(files)
+	}
+	t.mu.Lock()
+	maps.Copy(out.renderCache, t.renderCache)
+	maps.Copy(out.namespaceCache, t.namespaceCache)
+	t.mu.Unlock()
+	out.Loader = cloneLoader(t.Loader)
+	out.Translator = t.Translator
+	out.Observer = t.Observer
+	out.OnRenderError = t.OnRenderError
+	out.ScopedClassPrefix = t.ScopedClassPrefix
+	out.DuplicateDefines = t.DuplicateDefines
+	out.MissingKeys = t.MissingKeys
+	out.StrictGlobalRefs = t.StrictGlobalRefs
+	out.TrimDirectiveLines = t.TrimDirectiveLines
+	out.DefaultEntry = t.DefaultEntry
+	out.DataMiddleware = slices.Clone(t.DataMiddleware)
+	out.PreprocessData = maps.Clone(t.PreprocessData)
+	return out
+}
+
+// Merge folds another group's funcs and loader into this group.  Funcs from
+// other override funcs with the same name, and other's loader is searched
+// before this group's loader, so other acts as a layer of customizations on
+// top of this group.  Compiled template caches are cleared since the
+// available funcs and templates may have changed.
+// Returns the template group for method chaining.
+func (t *TemplateGroup) Merge(other *TemplateGroup) *TemplateGroup {
+	if other == nil {
+		return t
+	}
+	maps.Copy(t.Funcs, other.Funcs)
+	if other.Translator != nil {
+		t.Translator = other.Translator
+	}
+	if other.ScopedClassPrefix != nil {
+		t.ScopedClassPrefix = other.ScopedClassPrefix
+	}
+	for ns, funcs := range other.namespaceFuncs {
+		t.RegisterNamespaceFuncs(ns, funcs)
+	}
+	for name, files := range other.bundles {
+		t.bundles[name] = slices.Clone(files)
+	}
+	t.DataMiddleware = append(t.DataMiddleware, other.DataMiddleware...)
+	if len(other.PreprocessData) > 0 {
+		if t.PreprocessData == nil {
+			t.PreprocessData = make(map[string]any)
+		}
+		maps.Copy(t.PreprocessData, other.PreprocessData)
+	}
+	switch {
+	case other.Loader == nil:
+	case t.Loader == nil:
+		t.Loader = cloneLoader(other.Loader)
+	default:
+		t.Loader = (&LoaderList{}).AddLoader(other.Loader).AddLoader(t.Loader)
+	}
+	t.clearCaches()
+	return t
+}
+
+// cloneLoader copies a LoaderList so it can be extended independently.
+// Other loaders are returned as is.
+func cloneLoader(loader TemplateLoader) TemplateLoader {
+	if list, ok := loader.(*LoaderList); ok && list != nil {
+		return &LoaderList{
+			DefaultLoader: list.DefaultLoader,
+			loaders:       append([]TemplateLoader{}, list.loaders...),
+		}
+	}
+	return loader
+}
+
+// MissingKeyMode is the missingkey option compiled templates are created with.
+type MissingKeyMode int
+
+const (
+	// MissingKeyDefault renders missing map keys as "<no value>" in text
+	// templates (and nothing in html templates).
+	MissingKeyDefault MissingKeyMode = iota
+
+	// MissingKeyZero renders missing map keys as the zero value of the map's
+	// element type.
+	MissingKeyZero
+
+	// MissingKeyError fails the render on a missing map key.
+	MissingKeyError
+)
+
+// option returns the template option for the mode.
+func (m MissingKeyMode) option() string {
+	switch m {
+	case MissingKeyZero:
+		return "missingkey=zero"
+	case MissingKeyError:
+		return "missingkey=error"
+	}
+	return "missingkey=default"
+}
+
 // NewHtmlTemplate creates a new HTML template with the given name.
 // The template will have access to the group's functions and any additional
 // functions provided.
 func (t *TemplateGroup) NewHtmlTemplate(name string, funcs map[string]any) (out *htmpl.Template) {
-	out = htmpl.New(name).Funcs(t.Funcs)
+	out = htmpl.New(name).Option(t.MissingKeys.option())
+	out = out.Funcs(htmlBuiltins(out)).Funcs(t.Funcs)
 	if funcs != nil {
 		out = out.Funcs(funcs)
 	}
@@ -72,7 +311,8 @@ func (t *TemplateGroup) NewHtmlTemplate(name string, funcs map[string]any) (out
 // The template will have access to the group's functions and any additional
 // functions provided.
 func (t *TemplateGroup) NewTextTemplate(name string, funcs map[string]any) (out *ttmpl.Template) {
-	out = ttmpl.New(name).Funcs(t.Funcs)
+	out = ttmpl.New(name).Option(t.MissingKeys.option())
+	out = out.Funcs(textBuiltins(out)).Funcs(t.Funcs)
 	if funcs != nil {
 		out = out.Funcs(funcs)
 	}
@@ -88,29 +328,56 @@ func (t *TemplateGroup) PreProcessTextTemplate(root *Template, funcs ttmpl.FuncM
 		name = root.Path
 	}
 	if name != "" {
-		out = t.textTemplates[name]
+		out = t.cachedText(name)
 	}
 	if true || out == nil {
 		// try and load it
 		out = t.NewTextTemplate(name, funcs)
-		err = root.WalkTemplate(t.Loader, func(t *Template) error {
-			if t.Path == "" {
-				out, err = out.Parse(t.ParsedSource)
+		root.requirements = nil
+		bound := make(map[string]any)
+		defines := newDefineTracker(t.DuplicateDefines)
+		lookup := func(name string) *parse.Tree {
+			if tmpl := out.Lookup(name); tmpl != nil {
+				return tmpl.Tree
+			}
+			return nil
+		}
+		add := func(name string, tree *parse.Tree) error {
+			_, err := out.AddParseTree(name, tree)
+			return err
+		}
+		group := t
+		err = root.walkTemplate(t.observeLoader(t.Loader), t.TrimDirectiveLines, t.PreprocessData, t.bundles, func(t *Template) error {
+			collectRequires(root, t)
+			if err := defines.record(t); err != nil {
 				return panicOrError(err)
-			} else {
-				x, err := out.Parse(t.ParsedSource)
-				if err != nil {
+			}
+			if len(t.FuncBindings) > 0 {
+				if err := group.bindFuncs(t.FuncBindings, funcs, bound); err != nil {
 					return panicOrError(err)
 				}
-				// TODO - is this really necessary to add the parsed source back to out
-				// Should the parsing already do that for "out" anyway?
-				base := filepath.Base(t.Path)
-				out, err = out.AddParseTree(base, x.Tree)
-				return panicOrError(err)
+				out.Funcs(bound)
 			}
+			err := defines.parse(t, lookup, add, func(source string) error {
+				if t.Path == "" {
+					out, err = out.Parse(source)
+					return err
+				} else {
+					x, err := out.Parse(source)
+					if err != nil {
+						return err
+					}
+					// TODO - is this really necessary to add the parsed source back to out
+					// Should the parsing already do that for "out" anyway?
+					base := filepath.Base(t.Path)
+					out, err = out.AddParseTree(base, x.Tree)
+					return err
+				}
+			})
+			return panicOrError(err)
 		})
 		if err == nil && name != "" {
-			t.textTemplates[name] = out
+			t.cacheText(name, out)
 		}
 	}
 	return out, err
@@ -120,27 +387,68 @@ func (t *TemplateGroup) PreProcessTextTemplate(root *Template, funcs ttmpl.FuncM
 // that can be used for rendering. It handles template dependencies recursively.
 // Returns the processed template and any error encountered.
 func (t *TemplateGroup) PreProcessHtmlTemplate(root *Template, funcs htmpl.FuncMap) (out *htmpl.Template, err error) {
+	return t.preProcessHtmlTemplate(root, funcs, t.Loader)
+}
+
+// preProcessHtmlTemplate is PreProcessHtmlTemplate with an explicit loader
+// used to resolve the template's dependencies.
+func (t *TemplateGroup) preProcessHtmlTemplate(root *Template, funcs htmpl.FuncMap, loader TemplateLoader) (out *htmpl.Template, err error) {
 	name := root.Name
 	if name == "" {
 		name = root.Path
 	}
 	if name != "" {
-		out = t.htmlTemplates[name]
+		out = t.cachedHtml(name)
 	}
 	if true || out == nil {
 		// try and load it
-		out = htmpl.New(name).Funcs(t.Funcs)
-		if funcs != nil {
-			out = out.Funcs(funcs)
-		}
+		out = t.NewHtmlTemplate(name, funcs)
 
 		// Collect all extensions from all processed templates
 		var allExtensions []Extension
+		importedNamespaces := make(map[string]bool)
+		bound := make(map[string]any)
+		allOverrides := make(map[string]string)
+		var allUsings []string
+		root.requirements = nil
+		root.appliedExtensions = nil
+		root.shakenImports = nil
+		defines := newDefineTracker(t.DuplicateDefines)
+		lookup := func(name string) *parse.Tree {
+			if tmpl := out.Lookup(name); tmpl != nil {
+				return tmpl.Tree
+			}
+			return nil
+		}
+		add := func(name string, tree *parse.Tree) error {
+			_, err := out.AddParseTree(name, tree)
+			return err
+		}
 
-		w := Walker{Loader: t.Loader,
+		// The walk's content is copied into each ParsedSource, so its buffer
+		// can go back to the pool once the walk is done
+		buf := getBuffer()
+		defer putBuffer(buf)
+		w := Walker{Buffer: buf, Loader: t.observeLoader(loader), Data: t.PreprocessData, TrimDirectiveLines: t.TrimDirectiveLines, Bundles: t.bundles,
 			ProcessedTemplate: func(curr *Template) error {
+				collectRequires(root, curr)
+				if err := defines.record(curr); err != nil {
+					return panicOrError(err)
+				}
+
 				// Collect extensions from this template
 				allExtensions = append(allExtensions, curr.Extensions...)
+				maps.Copy(allOverrides, curr.Overrides)
+				allUsings = append(allUsings, curr.Usings...)
+				if curr.Namespace != "" {
+					importedNamespaces[curr.Namespace] = true
+				}
+				if len(curr.FuncBindings) > 0 {
+					if err := t.bindFuncs(curr.FuncBindings, funcs, bound); err != nil {
+						return panicOrError(err)
+					}
+					out.Funcs(bound)
+				}
 
 				// Skip non-root templates that don't have a namespace and no entry points
 				// (they will be processed via normal include mechanism)
@@ -149,27 +457,39 @@ func (t *TemplateGroup) PreProcessHtmlTemplate(root *Template, funcs htmpl.FuncM
 				}
 
 				if curr.Path == "" {
-					out, err = out.Parse(curr.ParsedSource)
+					err := defines.parse(curr, lookup, add, func(source string) (err error) {
+						out, err = out.Parse(source)
+						return err
+					})
 					return panicOrError(err)
 				}
 
-				// If namespace is set, parse into a temporary template and apply namespacing
-				if curr.Namespace != "" {
-					return t.processNamespacedTemplate(curr, out, funcs)
-				}
-
-				// If entry points are set (selective include), apply tree-shaking
-				if len(curr.NamespaceEntryPoints) > 0 {
-					return t.processSelectiveInclude(curr, out, funcs)
+				// If namespace is set, parse into a temporary template and apply namespacing.
+				// If entry points are set (selective include), apply tree-shaking.
+				if curr.Namespace != "" || len(curr.NamespaceEntryPoints) > 0 {
+					var shaken ShakenImport
+					var err error
+					if curr.Namespace != "" {
+						shaken, err = t.processNamespacedTemplate(curr, out, withFuncs(funcs, bound))
+					} else {
+						shaken, err = t.processSelectiveInclude(curr, out, withFuncs(funcs, bound))
+					}
+					if err == nil && len(curr.NamespaceEntryPoints) > 0 {
+						root.shakenImports = append(root.shakenImports, shaken)
+					}
+					return err
 				}
 
 				// Normal case: parse and add with original name
 				base := filepath.Base(curr.Path)
-				x, err := out.Parse(curr.ParsedSource)
-				if err != nil {
-					return panicOrError(err)
-				}
-				out, err = out.AddParseTree(base, x.Tree)
+				err := defines.parse(curr, lookup, add, func(source string) error {
+					x, err := out.Parse(source)
+					if err != nil {
+						return err
+					}
+					out, err = out.AddParseTree(base, x.Tree)
+					return err
+				})
 				return panicOrError(err)
 			}}
 		err = w.Walk(root)
@@ -177,14 +497,31 @@ func (t *TemplateGroup) PreProcessHtmlTemplate(root *Template, funcs htmpl.FuncM
 			return out, err
 		}
 
-		// Process all collected extensions after all templates are parsed
-		err = t.processExtensionsList(allExtensions, out)
+		// Aliases from using directives come first so extends and overrides
+		// can refer to them
+		if err = t.processUsings(allUsings, importedNamespaces, out); err != nil {
+			return out, panicOrError(err)
+		}
+
+		// Process all collected extensions after all templates are parsed,
+		// followed by those added with Extend
+		allExtensions = append(allExtensions, root.extraExtensions...)
+		root.Extensions = allExtensions
+		if err = checkExtendNamespaces(allExtensions, importedNamespaces, out); err != nil {
+			return out, panicOrError(err)
+		}
+		root.appliedExtensions, err = t.processExtensionsList(allExtensions, out)
 		if err != nil {
 			return out, err
 		}
 
+		// Overrides apply last so they also rewire references in extended templates
+		if err = t.processOverrides(allOverrides, out); err != nil {
+			return out, err
+		}
+
 		if name != "" {
-			t.htmlTemplates[name] = out
+			t.cacheHtml(name, out)
 		}
 	}
 	return out, err
@@ -192,93 +529,124 @@ func (t *TemplateGroup) PreProcessHtmlTemplate(root *Template, funcs htmpl.FuncM
 
 // processNamespacedTemplate handles templates that should be added to a namespace.
 // It parses the template, applies tree-shaking if entry points are specified,
-// and adds all reachable templates with namespaced names.
-func (t *TemplateGroup) processNamespacedTemplate(curr *Template, out *htmpl.Template, funcs htmpl.FuncMap) error {
+// and adds all reachable templates with namespaced names.  The rewritten
+// trees are cached (see namespaceCache) so other roots importing the same
+// file the same way skip the parsing and rewriting.
+func (t *TemplateGroup) processNamespacedTemplate(curr *Template, out *htmpl.Template, funcs htmpl.FuncMap) (shaken ShakenImport, err error) {
 	slog.Debug("processNamespacedTemplate", "path", curr.Path, "namespace", curr.Namespace)
 
+	// Namespace-scoped funcs are renamed to unique names in the copied trees
+	// so they are only visible to templates in this namespace
+	nsFuncs := t.namespaceFuncs[curr.Namespace]
+	var funcRenames map[string]string
+	if len(nsFuncs) > 0 {
+		funcRenames = make(map[string]string, len(nsFuncs))
+		mangledFuncs := make(htmpl.FuncMap, len(nsFuncs))
+		for name, fn := range nsFuncs {
+			mangled := namespacedFuncName(curr.Namespace, name)
+			funcRenames[name] = mangled
+			mangledFuncs[mangled] = fn
+		}
+		out.Funcs(mangledFuncs)
+	}
+
+	classPrefix := ""
+	if t.ScopedClassPrefix != nil {
+		classPrefix = t.ScopedClassPrefix(curr.Namespace)
+	}
+
+	cached, hit := t.cachedNamespace(curr, classPrefix)
+	if curr.Path != "" {
+		t.observeCache(namespaceCacheKey(curr), hit)
+	}
+	if !hit {
+		if cached, err = t.rewriteNamespace(curr, funcs, funcRenames, classPrefix); err != nil {
+			return shaken, err
+		}
+		t.cacheNamespace(curr, cached)
+	}
+	if err := t.checkShadowedGlobals(curr, cached.shadowed); err != nil {
+		return shaken, panicOrError(err)
+	}
+
+	// Add copies of the namespaced templates to output, as html/template
+	// modifies trees when escaping them
+	for namespacedName, tree := range cached.trees {
+		out, err = out.AddParseTree(namespacedName, tree.Copy())
+		if err != nil {
+			return shaken, panicOrError(err)
+		}
+	}
+	return cached.shakenImport(), nil
+}
+
+// rewriteNamespace parses a namespaced template and returns the trees
+// reachable from its entry points, renamed and rewritten for its namespace.
+func (t *TemplateGroup) rewriteNamespace(curr *Template, funcs htmpl.FuncMap, funcRenames map[string]string, classPrefix string) (*cachedNamespace, error) {
 	// Parse into a fresh temporary template to avoid name collisions
-	temp := htmpl.New("temp").Funcs(t.Funcs)
-	if funcs != nil {
-		temp = temp.Funcs(funcs)
+	temp := t.NewHtmlTemplate("temp", funcs)
+	if nsFuncs := t.namespaceFuncs[curr.Namespace]; len(nsFuncs) > 0 {
+		temp = temp.Funcs(nsFuncs)
 	}
 	temp, err := temp.Parse(curr.ParsedSource)
 	if err != nil {
-		return panicOrError(err)
+		return nil, panicOrError(err)
 	}
 
 	// Build map of all templates for tree-shaking
-	allTemplates := make(map[string]*htmpl.Template)
-	var allNames []string
+	treesMap := make(map[string]*parse.Tree)
 	for _, tmpl := range temp.Templates() {
 		if tmpl.Tree != nil && tmpl.Name() != "temp" {
-			allTemplates[tmpl.Name()] = tmpl
-			allNames = append(allNames, tmpl.Name())
+			treesMap[tmpl.Name()] = tmpl.Tree
 		}
 	}
-	// slog.Debug("processNamespacedTemplate: found templates", "path", curr.Path, "templates", allNames)
 
-	// Determine which templates to include
-	var templatesToInclude map[string]bool
-	if len(curr.NamespaceEntryPoints) > 0 {
-		// Tree-shaking: only include reachable templates
-		treesMap := make(map[string]*parse.Tree)
-		for name, tmpl := range allTemplates {
-			treesMap[name] = tmpl.Tree
-		}
-		templatesToInclude = ComputeReachableTemplates(treesMap, curr.NamespaceEntryPoints)
-	} else {
-		// Include all templates
-		templatesToInclude = make(map[string]bool)
-		for _, name := range allNames {
-			templatesToInclude[name] = true
+	// Determine which templates to include (all of them unless tree-shaking
+	// via entry points) along with their final namespaced names
+	rewrites := ComputeNamespacedReachableTemplates(treesMap, curr.NamespaceEntryPoints, curr.Namespace)
+	if curr.NamespacePath != "" {
+		for name := range rewrites {
+			rewrites[name] = curr.namespacedName(name)
 		}
 	}
-
-	// Build rewrite map for all templates being included
-	rewrites := make(map[string]string)
-	for name := range templatesToInclude {
-		rewrites[name] = TransformName(name, curr.Namespace)
+	cached := &cachedNamespace{
+		source:      curr.ParsedSource,
+		classPrefix: classPrefix,
+		shaken:      newShakenImport(curr, treesMap, rewrites),
+		trees:       make(map[string]*parse.Tree, len(rewrites)),
 	}
+	cached.shaken.EntryPoints = slices.Clone(cached.shaken.EntryPoints)
+	cached.shadowed = shadowedGlobalRefs(treesMap, rewrites)
 
-	// Add namespaced templates to output
-	var createdNames []string
-	for name := range templatesToInclude {
-		tmpl := allTemplates[name]
-		if tmpl == nil || tmpl.Tree == nil {
+	for name, namespacedName := range rewrites {
+		tree := treesMap[name]
+		if tree == nil {
 			continue
 		}
 
 		// Copy tree and apply namespace rewrites
-		copiedTree := tmpl.Tree.Copy()
+		copiedTree := tree.Copy()
 		WalkParseTree(copiedTree.Root, func(node *parse.TemplateNode) {
 			// Apply full namespace transformation rules
-			node.Name = TransformName(node.Name, curr.Namespace)
+			node.Name = curr.namespacedName(node.Name)
 		})
-
-		namespacedName := rewrites[name]
+		transformBuiltinArgs(copiedTree, curr.namespacedName)
+		RenameFuncs(copiedTree, funcRenames)
+		PrefixClasses(copiedTree, classPrefix)
 		copiedTree.Name = namespacedName
-		out, err = out.AddParseTree(namespacedName, copiedTree)
-		if err != nil {
-			return panicOrError(err)
-		}
-		createdNames = append(createdNames, namespacedName)
+		cached.trees[namespacedName] = copiedTree
 	}
-	// slog.Debug("processNamespacedTemplate: created templates", "path", curr.Path, "created", createdNames)
-
-	return nil
+	return cached, nil
 }
 
 // processSelectiveInclude handles templates with entry points but no namespace.
 // It applies tree-shaking to only include the specified templates and their dependencies.
-func (t *TemplateGroup) processSelectiveInclude(curr *Template, out *htmpl.Template, funcs htmpl.FuncMap) error {
+func (t *TemplateGroup) processSelectiveInclude(curr *Template, out *htmpl.Template, funcs htmpl.FuncMap) (shaken ShakenImport, err error) {
 	// Parse into a fresh temporary template
-	temp := htmpl.New("temp").Funcs(t.Funcs)
-	if funcs != nil {
-		temp = temp.Funcs(funcs)
-	}
-	temp, err := temp.Parse(curr.ParsedSource)
+	temp := t.NewHtmlTemplate("temp", funcs)
+	temp, err = temp.Parse(curr.ParsedSource)
 	if err != nil {
-		return panicOrError(err)
+		return shaken, panicOrError(err)
 	}
 
 	// Build map of all templates for tree-shaking
@@ -293,6 +661,7 @@ func (t *TemplateGroup) processSelectiveInclude(curr *Template, out *htmpl.Templ
 
 	// Compute reachable templates
 	templatesToInclude := ComputeReachableTemplates(treesMap, curr.NamespaceEntryPoints)
+	shaken = newShakenImport(curr, treesMap, templatesToInclude)
 
 	// Add only reachable templates to output
 	for name := range templatesToInclude {
@@ -303,22 +672,55 @@ func (t *TemplateGroup) processSelectiveInclude(curr *Template, out *htmpl.Templ
 
 		out, err = out.AddParseTree(name, tmpl.Tree)
 		if err != nil {
-			return panicOrError(err)
+			return shaken, panicOrError(err)
 		}
 	}
 
+	return shaken, nil
+}
+
+// Extend records an extension of root that creates dest as a copy of source
+// with the given rewrites (block name -> replacement), as if root contained
+// {{# extend source dest block replacement ... #}}, eg for layouts computed
+// at runtime without writing directives into template source.  It is applied
+// after root's own extend directives every time root is compiled as a root
+// from now on, so it can extend templates those directives create.  Returns
+// an error if the extension is invalid (see NewExtension).
+func (t *TemplateGroup) Extend(root *Template, source, dest string, rewrites map[string]string) error {
+	ext, err := NewExtension(source, dest, rewrites)
+	if err != nil {
+		return err
+	}
+	root.extraExtensions = append(root.extraExtensions, ext)
 	return nil
 }
 
 // processExtensions processes all extend directives recorded on the root template.
 // For each extension, it copies the source template and rewires references.
-func (t *TemplateGroup) processExtensions(root *Template, out *htmpl.Template) error {
-	return t.processExtensionsList(root.Extensions, out)
+func (t *TemplateGroup) processExtensions(root *Template, out *htmpl.Template) (err error) {
+	root.appliedExtensions, err = t.processExtensionsList(root.Extensions, out)
+	return err
+}
+
+// checkExtendNamespaces reports extensions whose source template is in a
+// namespace that no namespace directive imported, which is usually a typo in
+// the namespace name.  Sources that exist anyway (eg a template explicitly
+// defined with a "NS:" name) are allowed.
+func checkExtendNamespaces(extensions []Extension, imported map[string]bool, out *htmpl.Template) error {
+	for _, ext := range extensions {
+		namespace, _, found := strings.Cut(ext.SourceTemplate, ":")
+		if !found || namespace == "" || imported[namespace] || out.Lookup(ext.SourceTemplate) != nil {
+			continue
+		}
+		return fmt.Errorf("extend references namespace '%s' which was never imported (extending %s as %s)", namespace, ext.SourceTemplate, ext.DestTemplate)
+	}
+	return nil
 }
 
 // processExtensionsList processes a list of extensions.
 // For each extension, it copies the source template and rewires references.
-func (t *TemplateGroup) processExtensionsList(extensions []Extension, out *htmpl.Template) error {
+// Returns a record of each extension processed (up to and including any that failed).
+func (t *TemplateGroup) processExtensionsList(extensions []Extension, out *htmpl.Template) (applied []AppliedExtension, err error) {
 	if false && len(extensions) > 0 {
 		// Log available templates for debugging
 		var availableNames []string
@@ -335,21 +737,49 @@ func (t *TemplateGroup) processExtensionsList(extensions []Extension, out *htmpl
 		// Find the source template
 		sourceTmpl := out.Lookup(ext.SourceTemplate)
 		if sourceTmpl == nil || sourceTmpl.Tree == nil {
-			return fmt.Errorf("extend: source template not found: %s", ext.SourceTemplate)
+			applied = append(applied, AppliedExtension{Extension: ext})
+			return applied, fmt.Errorf("extend: source template not found: %s", ext.SourceTemplate)
 		}
+		applied = append(applied, newAppliedExtension(ext, sourceTmpl.Tree))
 
 		// Copy the tree and apply rewrites
 		copiedTree := CopyTreeWithRewrites(sourceTmpl.Tree, ext.Rewrites)
 		copiedTree.Name = ext.DestTemplate
 
 		// Add the new template
-		var err error
 		out, err = out.AddParseTree(ext.DestTemplate, copiedTree)
 		if err != nil {
-			return panicOrError(err)
+			return applied, panicOrError(err)
+		}
+	}
+
+	return applied, nil
+}
+
+// processOverrides rewires every reference to an overridden template, across
+// all templates in out, to its replacement.  The replacement templates
+// themselves are left untouched so they can still delegate to the original.
+func (t *TemplateGroup) processOverrides(overrides map[string]string, out *htmpl.Template) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+	replacements := make(map[string]bool)
+	for original, replacement := range overrides {
+		if out.Lookup(replacement) == nil {
+			return fmt.Errorf("override: replacement template not found: %s (for %s)", replacement, original)
 		}
+		replacements[replacement] = true
 	}
 
+	for _, tmpl := range out.Templates() {
+		if tmpl.Tree == nil || replacements[tmpl.Name()] {
+			continue
+		}
+		copiedTree := CopyTreeWithRewrites(tmpl.Tree, overrides)
+		if _, err := out.AddParseTree(tmpl.Name(), copiedTree); err != nil {
+			return panicOrError(err)
+		}
+	}
 	return nil
 }
 
@@ -359,16 +789,58 @@ func (t *TemplateGroup) processExtensionsList(extensions []Extension, out *htmpl
 // and applies any additional template functions provided.
 //
 // If entry is specified, it executes that specific template within the processed template.
+// Otherwise the root's Name, then the group's DefaultEntry, is used.
+//
+// Output is written to w incrementally as the template executes rather than
+// buffered, so it can be streamed to a client or teed with io.MultiWriter.
+// As a result, w may have received partial output when an error is
+// returned; render into a bytes.Buffer first when that matters.  With a
+// layout, the page's output is buffered and passed to the layout, whose
+// output is streamed.
 func (t *TemplateGroup) RenderHtmlTemplate(w io.Writer, root *Template, entry string, data any, funcs map[string]any) (err error) {
-	out, err := t.PreProcessHtmlTemplate(root, funcs)
+	return t.renderHtml(w, root, entry, data, funcs, t.Loader)
+}
+
+// renderHtml is RenderHtmlTemplate with an explicit loader used to resolve
+// the template's dependencies and layouts.
+func (t *TemplateGroup) renderHtml(w io.Writer, root *Template, entry string, data any, funcs map[string]any, loader TemplateLoader) (err error) {
+	if t.Observer != nil {
+		defer t.observeRender(time.Now(), root, entry, &err)
+	}
+	if t.OnRenderError != nil {
+		defer t.reportRenderError(root, entry, &err)
+	}
+	out, err := t.preProcessHtmlTemplate(root, funcs, loader)
 	if err != nil {
 		return panicOrError(err)
 	}
-	tmpl := htmpl.Must(out, err)
+	if layout := layoutOf(root); entry == "" && layout != "" {
+		return t.renderInLayout(w, root, out, layout, data, funcs, loader, map[string]bool{root.sourceName(): true})
+	}
+	return t.executeHtml(w, root, out, entry, data)
+}
+
+// executeHtml renders entry (or the root's body) of a preprocessed html
+// template, applying the group's DataMiddleware, requires checks and builtins.
+func (t *TemplateGroup) executeHtml(w io.Writer, root *Template, out *htmpl.Template, entry string, data any) (err error) {
+	tmpl := out
 	name := entry
 	if name == "" {
 		name = root.Name
 	}
+	if name == "" && t.DefaultEntry != "" && tmpl.Lookup(t.DefaultEntry) != nil {
+		name = t.DefaultEntry
+	}
+	data = withDefaults(root, data)
+	if data, err = t.applyDataMiddleware(name, data); err != nil {
+		return panicOrError(err)
+	}
+	if err := checkRequires(root, data); err != nil {
+		return panicOrError(err)
+	}
+	if _, ok := t.Funcs["t"]; !ok {
+		out.Funcs(htmpl.FuncMap{"t": t.translateFunc(data)})
+	}
 	if name == "" {
 		err = tmpl.Execute(w, data)
 	} else {
@@ -381,13 +853,51 @@ func (t *TemplateGroup) RenderHtmlTemplate(w io.Writer, root *Template, entry st
 	return
 }
 
+// applyDataMiddleware runs data through the group's DataMiddleware in order.
+func (t *TemplateGroup) applyDataMiddleware(entry string, data any) (any, error) {
+	for _, middleware := range t.DataMiddleware {
+		var err error
+		if data, err = middleware(entry, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// RenderJSONField renders a template as HTML and returns the result as a plain
+// string, for JSON APIs that return pre-rendered HTML snippets as fields.
+//
+// The result is already escaped for an HTML context (exactly as
+// RenderHtmlTemplate would write it) and must not be escaped again.  Place it
+// in a string field and let encoding/json handle the JSON escaping; on the
+// client, insert the decoded string as HTML (eg via innerHTML).  Returning a
+// string rather than template.HTML avoids it being re-escaped if it is
+// later passed back into another template by mistake.
+func (t *TemplateGroup) RenderJSONField(root *Template, entry string, data any) (string, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := t.RenderHtmlTemplate(buf, root, entry, data, nil); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 // RenderTextTemplate renders a template as plain text to the provided writer.
 //
 // It processes the template with its dependencies, executes it with the given data,
 // and applies any additional template functions provided.
 //
 // If entry is specified, it executes that specific template within the processed template.
+// Otherwise the root's Name, then the group's DefaultEntry, is used.
+//
+// As with RenderHtmlTemplate, output is written to w incrementally.
 func (t *TemplateGroup) RenderTextTemplate(w io.Writer, root *Template, entry string, data any, funcs map[string]any) (err error) {
+	if t.Observer != nil {
+		defer t.observeRender(time.Now(), root, entry, &err)
+	}
+	if t.OnRenderError != nil {
+		defer t.reportRenderError(root, entry, &err)
+	}
 	out, err := t.PreProcessTextTemplate(root, funcs)
 	if err != nil {
 		return panicOrError(err)
@@ -397,6 +907,19 @@ func (t *TemplateGroup) RenderTextTemplate(w io.Writer, root *Template, entry st
 	if name == "" {
 		name = root.Name
 	}
+	if name == "" && t.DefaultEntry != "" && tmpl.Lookup(t.DefaultEntry) != nil {
+		name = t.DefaultEntry
+	}
+	data = withDefaults(root, data)
+	if data, err = t.applyDataMiddleware(name, data); err != nil {
+		return panicOrError(err)
+	}
+	if err := checkRequires(root, data); err != nil {
+		return panicOrError(err)
+	}
+	if _, ok := t.Funcs["t"]; !ok {
+		out.Funcs(ttmpl.FuncMap{"t": t.translateFunc(data)})
+	}
 	if name == "" {
 		err = tmpl.Execute(w, data)
 	} else {
@@ -0,0 +1,320 @@
+package templar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// renderWith loads name from the group's loader and renders it as HTML.
+func renderWith(t *testing.T, group *TemplateGroup, name string, data any) string {
+	t.Helper()
+	templates, err := group.Loader.Load(name, "")
+	if err != nil {
+		t.Fatalf("Failed to load %s: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := group.RenderHtmlTemplate(&buf, templates[0], "", data, nil); err != nil {
+		t.Fatalf("Failed to render %s: %v", name, err)
+	}
+	return buf.String()
+}
+
+// TestTemplateGroup_CloneAndMerge verifies that a cloned group can be layered
+// with another group's funcs and templates without affecting the original.
+func TestTemplateGroup_CloneAndMerge(t *testing.T) {
+	base := NewTemplateGroup()
+	base.Loader = (&LoaderList{}).AddLoader(NewMapLoader(map[string]string{
+		"page.html":   `{{# include "header.html" #}}{{ template "header" . }}|{{ greet }}`,
+		"header.html": `{{ define "header" }}Base Header{{ end }}`,
+	}))
+	base.AddFuncs(map[string]any{"greet": func() string { return "hello" }})
+
+	tenant := NewTemplateGroup()
+	tenant.Loader = NewMapLoader(map[string]string{
+		"header.html": `{{ define "header" }}Tenant Header{{ end }}`,
+	})
+	tenant.AddFuncs(map[string]any{"greet": func() string { return "bonjour" }})
+
+	merged := base.Clone().Merge(tenant)
+
+	if got, want := renderWith(t, merged, "page.html", nil), "Tenant Header|bonjour"; got != want {
+		t.Errorf("merged render = %q, want %q", got, want)
+	}
+	if got, want := renderWith(t, base, "page.html", nil), "Base Header|hello"; got != want {
+		t.Errorf("base render = %q, want %q (base should be unaffected)", got, want)
+	}
+}
+
+// TestTemplateGroup_DuplicateDefines verifies each DuplicateDefinePolicy when
+// two included files define the same template.
+func TestTemplateGroup_DuplicateDefines(t *testing.T) {
+	files := map[string]string{
+		"page.html": `{{# include "a.html" #}}{{# include "b.html" #}}{{ template "footer" . }}`,
+		"a.html":    `{{ define "footer" }}A{{ end }}`,
+		"b.html":    `{{ define "footer" }}B{{ end }}`,
+	}
+	for _, tc := range []struct {
+		policy DuplicateDefinePolicy
+		want   string
+	}{{DefineLastWins, "B"}, {DefineFirstWins, "A"}} {
+		group := NewTemplateGroup()
+		group.Loader = NewMapLoader(files)
+		group.DuplicateDefines = tc.policy
+		if got := renderWith(t, group, "page.html", nil); got != tc.want {
+			t.Errorf("policy %d: got %q, want %q", tc.policy, got, tc.want)
+		}
+
+		// Text templates parse each include separately rather than flattened
+		templates, _ := group.Loader.Load("page.html", "")
+		var buf bytes.Buffer
+		if err := group.RenderTextTemplate(&buf, templates[0], "", nil, nil); err != nil || buf.String() != tc.want {
+			t.Errorf("policy %d (text): got %q, %v, want %q", tc.policy, buf.String(), err, tc.want)
+		}
+	}
+
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(files)
+	group.DuplicateDefines = DefineError
+	templates, _ := group.Loader.Load("page.html", "")
+	var buf bytes.Buffer
+	err := group.RenderHtmlTemplate(&buf, templates[0], "", nil, nil)
+	var dupErr *DuplicateDefineError
+	if !errors.As(err, &dupErr) || dupErr.Name != "footer" || dupErr.First != "a.html" || dupErr.Second != "b.html" {
+		t.Errorf("Expected DuplicateDefineError for footer in a.html and b.html, got %v", err)
+	}
+}
+
+// TestTemplateGroup_DuplicateDefinesBackquoted verifies that duplicate
+// defines written with backquoted names are resolved by the policy too, and
+// that DefineLastWins does not parse files to record their defines.
+func TestTemplateGroup_DuplicateDefinesBackquoted(t *testing.T) {
+	files := map[string]string{
+		"page.html": `{{# include "a.html" #}}{{# include "b.html" #}}{{ template "footer" . }}`,
+		"a.html":    "{{ define `footer` }}A{{ end }}",
+		"b.html":    "{{- define \"footer\" -}}B{{ end }}",
+	}
+	for _, tc := range []struct {
+		policy DuplicateDefinePolicy
+		want   string
+	}{{DefineLastWins, "B"}, {DefineFirstWins, "A"}} {
+		group := NewTemplateGroup()
+		group.Loader = NewMapLoader(files)
+		group.DuplicateDefines = tc.policy
+		if got := renderWith(t, group, "page.html", nil); got != tc.want {
+			t.Errorf("policy %d: got %q, want %q", tc.policy, got, tc.want)
+		}
+	}
+
+	tracker := newDefineTracker(DefineLastWins)
+	if err := tracker.record(&Template{Path: "a.html", RawSource: []byte(files["a.html"])}); err != nil || len(tracker.owners) != 0 {
+		t.Errorf("expected nothing recorded under DefineLastWins, got %v, %v", tracker.owners, err)
+	}
+}
+
+// TestTemplateGroup_RenderJSONField verifies that a rendered snippet is HTML
+// escaped once and survives a JSON round trip unchanged.
+func TestTemplateGroup_RenderJSONField(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"snippet.html": `{{ define "row" }}<td>{{ .Name }}</td>{{ end }}`,
+	})
+	templates, _ := group.Loader.Load("snippet.html", "")
+
+	html, err := group.RenderJSONField(templates[0], "row", map[string]any{"Name": `<b>"Bob"</b>`})
+	if err != nil {
+		t.Fatalf("RenderJSONField failed: %v", err)
+	}
+	want := `<td>&lt;b&gt;&#34;Bob&#34;&lt;/b&gt;</td>`
+	if html != want {
+		t.Errorf("got %q, want %q", html, want)
+	}
+
+	encoded, _ := json.Marshal(map[string]string{"html": html})
+	var decoded map[string]string
+	if err := json.Unmarshal(encoded, &decoded); err != nil || decoded["html"] != want {
+		t.Errorf("JSON round trip changed the snippet: %q, %v", decoded["html"], err)
+	}
+}
+
+// TestTemplateGroup_ResetCaches verifies that ResetCaches drops compiled and
+// rendered output while keeping funcs and the loader.
+func TestTemplateGroup_ResetCaches(t *testing.T) {
+	group := NewTemplateGroup()
+	group.AddFuncs(map[string]any{"shout": strings.ToUpper})
+	group.Loader = NewMapLoader(map[string]string{"a.html": `{{ shout "a" }}`})
+
+	renderWith(t, group, "a.html", nil)
+	group.RenderCached("key", "v1", func() (string, error) { return "cached", nil })
+	group.ResetCaches()
+
+	if len(group.htmlTemplates) != 0 {
+		t.Errorf("Expected no compiled templates after ResetCaches, got %d", len(group.htmlTemplates))
+	}
+	if got, _ := group.RenderCached("key", "v1", func() (string, error) { return "fresh", nil }); got != "fresh" {
+		t.Errorf("Expected render cache to be reset, got %q", got)
+	}
+	if got := renderWith(t, group, "a.html", nil); got != "A" {
+		t.Errorf("Expected funcs and loader to survive ResetCaches, got %q", got)
+	}
+}
+
+// TestTemplateGroup_DefaultEntry verifies that DefaultEntry is rendered when
+// no entry is given, falling back to the root body if it is not defined.
+func TestTemplateGroup_DefaultEntry(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"home.html":  `Body{{ define "page" }}Page{{ end }}`,
+		"plain.html": `Plain`,
+	})
+	group.DefaultEntry = "page"
+
+	if got := renderWith(t, group, "home.html", nil); got != "Page" {
+		t.Errorf("Expected DefaultEntry to be rendered, got %q", got)
+	}
+	if got := renderWith(t, group, "plain.html", nil); got != "Plain" {
+		t.Errorf("Expected root body without a DefaultEntry define, got %q", got)
+	}
+}
+
+// TestTemplateGroup_DataMiddleware verifies that middleware is chained in
+// order before rendering and that an error aborts the render.
+func TestTemplateGroup_DataMiddleware(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": `{{# requires "CSRF" #}}{{ define "form" }}{{ .CSRF }}:{{ .Title }}{{ end }}`,
+	})
+	group.DataMiddleware = append(group.DataMiddleware,
+		func(entry string, data any) (any, error) {
+			return map[string]any{"Title": data, "Entry": entry}, nil
+		},
+		func(entry string, data any) (any, error) {
+			data.(map[string]any)["CSRF"] = "token-for-" + entry
+			return data, nil
+		},
+	)
+	templates, _ := group.Loader.Load("page.html", "")
+
+	var buf bytes.Buffer
+	if err := group.RenderHtmlTemplate(&buf, templates[0], "form", "Hello", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got, want := buf.String(), "token-for-form:Hello"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	group.DataMiddleware = append(group.DataMiddleware, func(entry string, data any) (any, error) {
+		return nil, errors.New("denied")
+	})
+	buf.Reset()
+	if err := group.RenderHtmlTemplate(&buf, templates[0], "form", "Hello", nil); err == nil || buf.Len() > 0 {
+		t.Errorf("Expected middleware error to abort the render, got %v, %q", err, buf.String())
+	}
+}
+
+// TestTemplateGroup_Bundles verifies that the bundle directive includes every
+// file registered for the bundle, in both html and text modes.
+func TestTemplateGroup_Bundles(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":   `{{# bundle "forms" #}}{{ template "input" }}{{ template "select" }}`,
+		"input.html":  `{{ define "input" }}<input>{{ end }}`,
+		"select.html": `{{ define "select" }}<select>{{ end }}`,
+		"broken.html": `{{# bundle "missing" #}}`,
+	})
+	group.RegisterBundle("forms", []string{"input.html", "select.html"})
+
+	if got, want := renderWith(t, group, "page.html", nil), "<input><select>"; got != want {
+		t.Errorf("html: got %q, want %q", got, want)
+	}
+
+	templates, _ := group.Loader.Load("page.html", "")
+	var buf bytes.Buffer
+	if err := group.RenderTextTemplate(&buf, templates[0], "", nil, nil); err != nil {
+		t.Fatalf("RenderTextTemplate failed: %v", err)
+	}
+	if got, want := buf.String(), "<input><select>"; got != want {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+
+	templates, _ = group.Loader.Load("broken.html", "")
+	if _, err := group.PreProcessHtmlTemplate(templates[0], nil); err == nil {
+		t.Error("Expected an error for an unregistered bundle")
+	}
+}
+
+// TestTemplateGroup_MissingKeys verifies that MissingKeys sets the missingkey
+// option of compiled templates.
+func TestTemplateGroup_MissingKeys(t *testing.T) {
+	render := func(mode MissingKeyMode, asHtml bool) (string, error) {
+		group := NewTemplateGroup()
+		group.MissingKeys = mode
+		group.Loader = NewMapLoader(map[string]string{"page.txt": `[{{ .Name }}][{{ .Nmae }}]`})
+		templates, err := group.Loader.Load("page.txt", "")
+		if err != nil {
+			t.Fatalf("Failed to load: %v", err)
+		}
+		var buf bytes.Buffer
+		data := map[string]int{"Name": 1}
+		if asHtml {
+			err = group.RenderHtmlTemplate(&buf, templates[0], "", data, nil)
+		} else {
+			err = group.RenderTextTemplate(&buf, templates[0], "", data, nil)
+		}
+		return buf.String(), err
+	}
+
+	if got, err := render(MissingKeyDefault, false); err != nil || got != "[1][<no value>]" {
+		t.Errorf("text default: got %q, %v", got, err)
+	}
+	if got, err := render(MissingKeyDefault, true); err != nil || got != "[1][]" {
+		t.Errorf("html default: got %q, %v", got, err)
+	}
+	for _, asHtml := range []bool{false, true} {
+		if got, err := render(MissingKeyZero, asHtml); err != nil || got != "[1][0]" {
+			t.Errorf("html=%v zero: got %q, %v", asHtml, got, err)
+		}
+		if _, err := render(MissingKeyError, asHtml); err == nil || !strings.Contains(err.Error(), `map has no entry for key "Nmae"`) {
+			t.Errorf("html=%v error: expected missing key error, got %v", asHtml, err)
+		}
+	}
+}
+
+// TestTemplateGroup_RenderStreams verifies that output is written to the
+// writer while the template executes rather than buffered until the end, so
+// it can be streamed or teed with io.MultiWriter.
+func TestTemplateGroup_RenderStreams(t *testing.T) {
+	for _, asHtml := range []bool{true, false} {
+		var out, tee bytes.Buffer
+		var seen string
+		group := NewTemplateGroup()
+		group.AddFuncs(map[string]any{
+			"written": func() string { seen = out.String(); return "" },
+			"fail":    func() (string, error) { return "", errors.New("failed") },
+		})
+		group.Loader = NewMapLoader(map[string]string{
+			"page.html": `<p>before</p>{{ written }}<p>after</p>{{ fail }}<p>never</p>`,
+		})
+		templates, _ := group.Loader.Load("page.html", "")
+		w := io.MultiWriter(&out, &tee)
+
+		var err error
+		if asHtml {
+			err = group.RenderHtmlTemplate(w, templates[0], "", nil, nil)
+		} else {
+			err = group.RenderTextTemplate(w, templates[0], "", nil, nil)
+		}
+		if err == nil {
+			t.Fatalf("html=%v: expected an error from fail", asHtml)
+		}
+		if seen != "<p>before</p>" {
+			t.Errorf("html=%v: expected output before written to be streamed, got %q", asHtml, seen)
+		}
+		if want := "<p>before</p><p>after</p>"; out.String() != want || tee.String() != want {
+			t.Errorf("html=%v: expected partial output %q in both writers, got %q and %q", asHtml, want, out.String(), tee.String())
+		}
+	}
+}
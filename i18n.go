@@ -0,0 +1,42 @@
+package templar
+
+// Translator looks up translated messages.  Register one on a TemplateGroup
+// to expose it to templates as the "t" function:
+//
+//	{{ t "welcome.title" }}
+//	{{ t "cart.items" .Count }}
+type Translator interface {
+	// Translate returns the message for key, formatted with args.
+	Translate(key string, args ...any) string
+}
+
+// LocalizedTranslator is an optional interface for translators that serve
+// multiple locales.  When the render data has a "Locale" key (or field), the
+// group calls ForLocale with its value and uses the returned Translator for
+// that render.
+type LocalizedTranslator interface {
+	Translator
+
+	// ForLocale returns the translator for the given locale (e.g., "fr-CA").
+	ForLocale(locale string) Translator
+}
+
+// LocaleKey is the data key (or struct field) the active locale is read from.
+const LocaleKey = "Locale"
+
+// translateFunc returns the "t" template function bound to the translator
+// for the locale in data.  Without a Translator, keys are returned untranslated.
+func (t *TemplateGroup) translateFunc(data any) func(key string, args ...any) string {
+	translator := t.Translator
+	if lt, ok := translator.(LocalizedTranslator); ok {
+		if locale, ok := getPath(LocaleKey, data).(string); ok && locale != "" {
+			translator = lt.ForLocale(locale)
+		}
+	}
+	return func(key string, args ...any) string {
+		if translator == nil {
+			return key
+		}
+		return translator.Translate(key, args...)
+	}
+}
@@ -0,0 +1,58 @@
+package templar
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// catalog is a minimal LocalizedTranslator backed by per-locale maps.
+type catalog struct {
+	locale   string
+	messages map[string]map[string]string
+}
+
+func (c *catalog) Translate(key string, args ...any) string {
+	if msg, ok := c.messages[c.locale][key]; ok {
+		return fmt.Sprintf(msg, args...)
+	}
+	return key
+}
+
+func (c *catalog) ForLocale(locale string) Translator {
+	return &catalog{locale: locale, messages: c.messages}
+}
+
+// TestTranslator verifies that the "t" func uses the group's Translator and
+// the locale from the render data, falling back to the key when untranslated.
+func TestTranslator(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Translator = &catalog{locale: "en", messages: map[string]map[string]string{
+		"en": {"hello": "Hello %s"},
+		"fr": {"hello": "Bonjour %s"},
+	}}
+	root := &Template{RawSource: []byte(`{{ t "hello" .Name }}|{{ t "missing" }}`)}
+
+	for locale, want := range map[string]string{
+		"":   "Hello Ann|missing",
+		"fr": "Bonjour Ann|missing",
+	} {
+		var buf bytes.Buffer
+		data := map[string]any{"Name": "Ann", "Locale": locale}
+		if err := group.RenderHtmlTemplate(&buf, root, "", data, nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("locale %q: got %q, want %q", locale, got, want)
+		}
+	}
+
+	// Without a translator keys are returned as is
+	var buf bytes.Buffer
+	if err := NewTemplateGroup().RenderTextTemplate(&buf, root, "", map[string]any{"Name": "Ann"}, nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got, want := buf.String(), "hello|missing"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
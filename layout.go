@@ -0,0 +1,74 @@
+package templar
+
+import (
+	"fmt"
+	htmpl "html/template"
+	"io"
+	"maps"
+)
+
+// LayoutMetaKey is the Metadata key that selects the layout a page is
+// rendered in, eg {{# meta "layout" "base.html" #}}.
+//
+// When rendering a root's body as html, a root with a layout is rendered
+// first, and its output is then passed to the layout as .Content (typed as
+// trusted html).  Layouts may declare a layout of their own to form a chain,
+// eg page -> docs -> base.
+//
+// Layouts are loaded with the group's Loader relative to the page.  They are
+// rendered with a copy of the page's data (if it is a map[string]any) with
+// Content added, or otherwise with a map of Content and Data (the page's
+// data).
+const LayoutMetaKey = "layout"
+
+// layoutOf returns the layout declared in a template's Metadata, if any.
+func layoutOf(tmpl *Template) string {
+	layout, _ := tmpl.Metadata[LayoutMetaKey].(string)
+	return layout
+}
+
+// renderInLayout renders root (already preprocessed into out) and injects
+// the output into its layout, recursing up the layout chain.  Layouts are
+// loaded with loader.  seen guards against layouts that (transitively) use
+// themselves.
+func (t *TemplateGroup) renderInLayout(w io.Writer, root *Template, out *htmpl.Template, layout string, data any, funcs map[string]any, loader TemplateLoader, seen map[string]bool) error {
+	// The page's defaults are passed on to its layout (eg a default Title)
+	data = withDefaults(root, data)
+	content := getBuffer()
+	defer putBuffer(content)
+	if err := t.executeHtml(content, root, out, "", data); err != nil {
+		return err
+	}
+
+	layouts, err := loader.Load(layout, root.includeDir())
+	if err != nil {
+		return panicOrError(fmt.Errorf("layout %s (for %s): %w", layout, root.sourceName(), err))
+	}
+	layoutRoot := layouts[0]
+	if seen[layoutRoot.sourceName()] {
+		return panicOrError(fmt.Errorf("layout cycle: %s uses %s", root.sourceName(), layoutRoot.sourceName()))
+	}
+	seen[layoutRoot.sourceName()] = true
+
+	layoutOut, err := t.preProcessHtmlTemplate(layoutRoot, funcs, loader)
+	if err != nil {
+		return panicOrError(err)
+	}
+	layoutData := withContent(data, htmpl.HTML(content.String()))
+	if next := layoutOf(layoutRoot); next != "" {
+		return t.renderInLayout(w, layoutRoot, layoutOut, next, layoutData, funcs, loader, seen)
+	}
+	return t.executeHtml(w, layoutRoot, layoutOut, "", layoutData)
+}
+
+// withContent returns the data a layout is rendered with (see LayoutMetaKey).
+func withContent(data any, content htmpl.HTML) map[string]any {
+	out := make(map[string]any)
+	if m, ok := data.(map[string]any); ok {
+		maps.Copy(out, m)
+	} else {
+		out["Data"] = data
+	}
+	out["Content"] = content
+	return out
+}
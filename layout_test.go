@@ -0,0 +1,38 @@
+package templar
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestLayoutMeta verifies that a page declaring a layout is wrapped in it
+// (and in the layout's own layout), with its output available as .Content.
+func TestLayoutMeta(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"pages/post.html":     `{{# meta "layout" "layouts/docs.html" #}}<p>{{ .Title }}</p>`,
+		"pages/plain.html":    `<p>{{ .Title }}</p>`,
+		"pages/loop.html":     `{{# meta "layout" "layouts/loop.html" #}}x`,
+		"layouts/docs.html":   `{{# meta "layout" "./base.html" #}}<article>{{ .Content }}</article>`,
+		"layouts/base.html":   `<title>{{ .Title }}</title><main>{{ .Content }}</main>`,
+		"layouts/loop.html":   `{{# meta "layout" "./loop.html" #}}{{ .Content }}`,
+		"layouts/struct.html": `{{ .Data.Title }}:{{ .Content }}`,
+		"pages/struct.html":   `{{# meta "layout" "layouts/struct.html" #}}{{ .Title }}`,
+	})
+
+	data := map[string]any{"Title": "Hello"}
+	if got, want := renderWith(t, group, "pages/post.html", data), "<title>Hello</title><main><article><p>Hello</p></article></main>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := renderWith(t, group, "pages/plain.html", data), "<p>Hello</p>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := renderWith(t, group, "pages/struct.html", struct{ Title string }{"S"}), "S:S"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	templates, _ := group.Loader.Load("pages/loop.html", "")
+	if err := group.RenderHtmlTemplate(&bytes.Buffer{}, templates[0], "", nil, nil); err == nil {
+		t.Error("Expected an error for a layout cycle")
+	}
+}
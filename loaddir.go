@@ -0,0 +1,44 @@
+package templar
+
+import (
+	"errors"
+)
+
+// LoadDir eagerly loads and preprocesses every template file under dir so
+// servers can fail fast at startup if any template does not compile, instead
+// of on first request.  It does not warm anything: renders compile their root
+// afresh, so the first render of each template costs the same afterwards.
+//
+// Includes are resolved relative to dir first and then via the group's Loader.
+// The returned map is keyed by the template's path relative to dir and only
+// contains templates that compiled.  Per-file failures are joined into the
+// returned error, each as a *TemplateError naming the file.
+func (t *TemplateGroup) LoadDir(dir string) (map[string]*Template, error) {
+	dirLoader := NewFileSystemLoader(LocalFolder(dir))
+	names, err := dirLoader.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var loader TemplateLoader = dirLoader
+	if t.Loader != nil {
+		loader = (&LoaderList{}).AddLoader(dirLoader).AddLoader(t.Loader)
+	}
+
+	loaded := make(map[string]*Template)
+	var errs []error
+	for _, name := range names {
+		templates, err := dirLoader.Load(name, "")
+		if err != nil {
+			errs = append(errs, &TemplateError{Path: name, Err: err})
+			continue
+		}
+		root := templates[0]
+		if _, err := t.preProcessHtmlTemplate(root, nil, loader); err != nil {
+			errs = append(errs, &TemplateError{Path: name, Line: errorLine(err, root.Path), Err: err})
+			continue
+		}
+		loaded[name] = root
+	}
+	return loaded, errors.Join(errs...)
+}
@@ -0,0 +1,45 @@
+package templar
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// TestLoadDir verifies that LoadDir compiles every template in a directory,
+// resolves includes from the directory and the group's loader, and reports
+// per-file errors without aborting the others.
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"home.html":         `{{# include "partials/nav.html" #}}{{# include "shared.html" #}}{{ template "nav" . }}`,
+		"partials/nav.html": `{{ define "nav" }}Nav{{ end }}`,
+		"broken.html":       `{{ if .X }}unclosed`,
+		"notes.txt":         `not a template`,
+	} {
+		full := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{"shared.html": `{{ define "shared" }}Shared{{ end }}`})
+
+	loaded, err := group.LoadDir(dir)
+
+	var names []string
+	for name := range loaded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "home.html" || names[1] != "partials/nav.html" {
+		t.Errorf("Unexpected loaded templates: %v", names)
+	}
+
+	var te *TemplateError
+	if !errors.As(err, &te) || te.Path != "broken.html" {
+		t.Errorf("Expected a TemplateError for broken.html, got %v", err)
+	}
+}
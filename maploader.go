@@ -0,0 +1,67 @@
+package templar
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MapLoader serves templates from an in-memory map of name to source.
+// Useful for tests, REPLs and dynamically generated template sets.
+type MapLoader struct {
+	// Templates maps a template name (e.g. "components/button.html") to its source.
+	Templates map[string]string
+
+	// Extensions is a list of file extensions tried when a name has no extension.
+	Extensions []string
+}
+
+// NewMapLoader creates a loader serving the given templates.
+// Default extensions: .tmpl, .tmplus, .html.
+func NewMapLoader(templates map[string]string) *MapLoader {
+	if templates == nil {
+		templates = make(map[string]string)
+	}
+	return &MapLoader{
+		Templates: templates,
+		Extensions: []string{
+			"tmpl", "tmplus", "html",
+		},
+	}
+}
+
+// Set adds or replaces a template. Returns the loader for method chaining.
+func (m *MapLoader) Set(name string, source string) *MapLoader {
+	m.Templates[name] = source
+	return m
+}
+
+// Load returns the template registered under the given name.  Relative names
+// ("./x", "../x") are resolved against cwd.  If the name has no extension, each
+// of the loader's Extensions is tried in order.
+func (m *MapLoader) Load(name string, cwd string) ([]*Template, error) {
+	if cwd != "" && (strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../")) {
+		name = path.Join(cwd, name)
+	}
+	candidates := []string{name}
+	if path.Ext(name) == "" {
+		for _, ext := range m.Extensions {
+			candidates = append(candidates, fmt.Sprintf("%s.%s", name, ext))
+		}
+	}
+	for _, candidate := range candidates {
+		if source, ok := m.Templates[candidate]; ok {
+			return []*Template{{RawSource: []byte(source), Path: candidate}}, nil
+		}
+	}
+	return nil, TemplateNotFound
+}
+
+// List returns the names of all registered templates.
+func (m *MapLoader) List() ([]string, error) {
+	seen := make(map[string]bool)
+	for name := range m.Templates {
+		seen[name] = true
+	}
+	return sortedKeys(seen), nil
+}
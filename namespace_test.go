@@ -2,6 +2,9 @@ package templar
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -83,6 +86,48 @@ func TestNamespace_DiamondIncludes(t *testing.T) {
 	}
 }
 
+// TestNamespace_GlobalAndNamespaced tests that a file both included globally
+// and imported into a namespace by the same page yields two independent
+// copies of its defines, in either order.
+func TestNamespace_GlobalAndNamespaced(t *testing.T) {
+	shared := `{{ define "card" }}<div>{{ template "icon" . }}</div>{{ end }}
+{{ define "icon" }}[icon]{{ end }}`
+	body := "\n" + `{{# extend "X:card" "XCard" "X:icon" "myIcon" #}}
+{{ define "myIcon" }}[mine]{{ end }}
+{{ define "page" }}{{ template "card" . }}|{{ template "X:card" . }}|{{ template "XCard" . }}{{ end }}`
+	for name, imports := range map[string]string{
+		"include first":   "{{# include \"shared.html\" #}}\n{{# namespace \"X\" \"shared.html\" #}}",
+		"namespace first": "{{# namespace \"X\" \"shared.html\" #}}\n{{# include \"shared.html\" #}}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			files := map[string]string{"shared.html": shared, "page.html": imports + body}
+			result := loadAndRender(t, files, "page.html", "page", nil)
+			if want := "<div>[icon]</div>|<div>[icon]</div>|<div>[mine]</div>"; strings.TrimSpace(result) != want {
+				t.Errorf("expected %q, got %q", want, result)
+			}
+
+			mfs := NewMemFS()
+			for path, content := range files {
+				mfs.SetFile(path, []byte(content))
+			}
+			group := NewTemplateGroup()
+			group.Loader = &FileSystemLoader{Folders: []FSFolder{{FS: mfs, Path: "."}}, Extensions: []string{"html"}}
+			templates, err := group.Loader.Load("page.html", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			reachable, err := group.ReachableTemplates(templates[0], "page")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []string{"X:card", "X:icon", "XCard", "card", "icon", "myIcon", "page"}
+			if !reflect.DeepEqual(reachable, want) {
+				t.Errorf("expected %v, got %v", want, reachable)
+			}
+		})
+	}
+}
+
 func TestNamespace_EmptyNamespaceError(t *testing.T) {
 	mfs := NewMemFS()
 	mfs.SetFile("component.html", []byte(`{{ define "button" }}<button/>{{ end }}`))
@@ -182,6 +227,60 @@ func TestExtend_PartialOverride(t *testing.T) {
 	}
 }
 
+// TestExtend_AppliedExtensions verifies that the applied extensions of a
+// compiled root report which rewrites took effect and which did not.
+func TestExtend_AppliedExtensions(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"base.html": `{{ define "layout" }}{{ template "content" . }}{{ end }}{{ define "content" }}Default{{ end }}`,
+		"page.html": `{{# namespace "Base" "base.html" #}}
+{{# extend "Base:layout" "MyLayout" "Base:content" "myContent" "Base:sidebar" "mySidebar" #}}
+{{ define "myContent" }}Mine{{ end }}{{ define "mySidebar" }}Side{{ end }}`,
+	})
+	templates, _ := group.Loader.Load("page.html", "")
+	if _, err := group.PreProcessHtmlTemplate(templates[0], nil); err != nil {
+		t.Fatalf("PreProcess failed: %v", err)
+	}
+
+	applied := templates[0].AppliedExtensions()
+	if len(applied) != 1 {
+		t.Fatalf("Expected 1 applied extension, got %d", len(applied))
+	}
+	ext := applied[0]
+	if !ext.SourceFound || ext.DestTemplate != "MyLayout" {
+		t.Errorf("Unexpected extension: %+v", ext)
+	}
+	if len(ext.Applied) != 1 || ext.Applied["Base:content"] != "myContent" {
+		t.Errorf("Expected Base:content to be applied, got %v", ext.Applied)
+	}
+	if len(ext.Unused) != 1 || ext.Unused[0] != "Base:sidebar" {
+		t.Errorf("Expected Base:sidebar to be unused, got %v", ext.Unused)
+	}
+}
+
+// TestExtend_ExtensionNames verifies that after preprocessing, a root's
+// Extensions include extend directives from the templates it included.
+func TestExtend_ExtensionNames(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"base.html":    `{{ define "layout" }}{{ template "content" . }}{{ end }}{{ define "content" }}Default{{ end }}`,
+		"layouts.html": `{{# namespace "Base" "base.html" #}}{{# extend "Base:layout" "SharedLayout" "Base:content" "shared" #}}{{ define "shared" }}S{{ end }}`,
+		"page.html":    `{{# include "layouts.html" #}}{{# extend "Base:layout" "PageLayout" "Base:content" "mine" #}}{{ define "mine" }}M{{ end }}`,
+	})
+	templates, _ := group.Loader.Load("page.html", "")
+	root := templates[0]
+
+	for i := 0; i < 2; i++ {
+		if _, err := group.PreProcessHtmlTemplate(root, nil); err != nil {
+			t.Fatalf("PreProcess failed: %v", err)
+		}
+		names := root.ExtensionNames()
+		if len(names) != 2 || names[0] != "SharedLayout" || names[1] != "PageLayout" {
+			t.Errorf("pass %d: ExtensionNames() = %v", i, names)
+		}
+	}
+}
+
 func TestInclude_SelectiveInclude(t *testing.T) {
 	result := loadAndRender(t, map[string]string{
 		"forms.html": `{{ define "button" }}<button>Click</button>{{ end }}
@@ -195,3 +294,373 @@ func TestInclude_SelectiveInclude(t *testing.T) {
 		t.Errorf("Expected button, got: %s", result)
 	}
 }
+
+// TestNamespaceFuncs verifies that funcs registered for a namespace are only
+// visible to templates imported under that namespace and shadow global funcs
+// of the same name there.
+func TestNamespaceFuncs(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":    `{{# namespace "UI" "widgets.html" #}}{{ label }} {{ template "UI:button" . }}`,
+		"widgets.html": `{{ define "button" }}[{{ label }} {{ shout "ok" }}]{{ end }}`,
+	})
+	group.AddFuncs(map[string]any{"label": func() string { return "global" }})
+	group.RegisterNamespaceFuncs("UI", map[string]any{
+		"label": func() string { return "ui" },
+		"shout": func(s string) string { return s + "!" },
+	})
+
+	if got, want := renderWith(t, group, "page.html", nil), "global [ui ok!]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Namespace funcs must not leak into the host templates
+	group.Loader = NewMapLoader(map[string]string{"leak.html": `{{ shout "x" }}`})
+	templates, _ := group.Loader.Load("leak.html", "")
+	var buf bytes.Buffer
+	if err := group.RenderHtmlTemplate(&buf, templates[0], "", nil, nil); err == nil {
+		t.Errorf("Expected error using namespace-only func outside its namespace, got %q", buf.String())
+	}
+}
+
+// TestOverride_RewiresAllReferences verifies that an override directive
+// rewires references to a namespaced template everywhere in the compiled set,
+// while the replacement can still delegate to the original.
+func TestOverride_RewiresAllReferences(t *testing.T) {
+	result := loadAndRender(t, map[string]string{
+		"ui.html": `{{ define "icon" }}<i>default</i>{{ end }}
+{{ define "button" }}<button>{{ template "icon" . }}</button>{{ end }}
+{{ define "card" }}<div>{{ template "icon" . }}</div>{{ end }}`,
+		"page.html": `{{# namespace "UI" "ui.html" #}}
+{{# override "UI:icon" "myIcon" #}}
+{{ define "myIcon" }}<b>{{ template "UI:icon" . }}</b>{{ end }}
+{{ define "page" }}{{ template "UI:button" . }}{{ template "UI:card" . }}{{ end }}`,
+	}, "page.html", "page", nil)
+
+	if want := "<button><b><i>default</i></b></button><div><b><i>default</i></b></div>"; result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+}
+
+// TestExtend_ConditionalOnPreprocessData verifies that directives can be
+// chosen at compile time based on the group's PreprocessData.
+func TestExtend_ConditionalOnPreprocessData(t *testing.T) {
+	files := map[string]string{
+		"base.html": `{{ define "v1" }}V1[{{ template "content" . }}]{{ end }}{{ define "v2" }}V2[{{ template "content" . }}]{{ end }}{{ define "content" }}{{ end }}`,
+		"page.html": `{{# namespace "Base" "base.html" #}}
+{{# if .UseNewLayout #}}{{# extend "Base:v2" "Layout" "Base:content" "mine" #}}{{# else #}}{{# extend "Base:v1" "Layout" "Base:content" "mine" #}}{{# end #}}
+{{ define "mine" }}Mine{{ end }}{{ template "Layout" . }}`,
+	}
+	for _, useNew := range []bool{false, true} {
+		group := NewTemplateGroup()
+		group.Loader = NewMapLoader(files)
+		group.PreprocessData = map[string]any{"UseNewLayout": useNew}
+
+		want := "V1[Mine]"
+		if useNew {
+			want = "V2[Mine]"
+		}
+		if got := strings.TrimSpace(renderWith(t, group, "page.html", nil)); got != want {
+			t.Errorf("UseNewLayout=%v: got %q, want %q", useNew, got, want)
+		}
+	}
+}
+
+// TestScopedClassPrefix verifies that static class names in a namespace's
+// templates are prefixed, leaving the host page and dynamic classes alone.
+func TestScopedClassPrefix(t *testing.T) {
+	group := NewTemplateGroup()
+	group.ScopedClassPrefix = func(namespace string) string { return strings.ToLower(namespace) + "-" }
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": `{{# namespace "UI" "ui.html" #}}<div class="btn">{{ template "UI:button" . }}</div>`,
+		"ui.html":   `{{ define "button" }}<button data-class="x" class="btn  primary {{ .Extra }}" id="b">go</button>{{ end }}`,
+	})
+
+	got := renderWith(t, group, "page.html", map[string]any{"Extra": "wide"})
+	want := `<div class="btn"><button data-class="x" class="ui-btn  ui-primary wide" id="b">go</button></div>`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestExtend_UnimportedNamespace verifies that extending a template from a
+// namespace that was never imported reports the namespace, while extending
+// templates in imported namespaces still works.
+func TestExtend_UnimportedNamespace(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"base.html": `{{ define "layout" }}{{ template "content" . }}{{ end }}{{ define "content" }}Default{{ end }}`,
+		"typo.html": `{{# namespace "Base" "base.html" #}}{{# extend "Bsae:layout" "MyLayout" #}}`,
+		"ok.html":   `{{# namespace "Base" "base.html" #}}{{# extend "Base:layout" "MyLayout" #}}{{ template "MyLayout" . }}`,
+	})
+
+	templates, _ := group.Loader.Load("typo.html", "")
+	_, err := group.PreProcessHtmlTemplate(templates[0], nil)
+	if err == nil || !strings.Contains(err.Error(), "extend references namespace 'Bsae' which was never imported") {
+		t.Errorf("Expected unimported namespace error, got %v", err)
+	}
+
+	if got := renderWith(t, group, "ok.html", nil); got != "Default" {
+		t.Errorf("got %q, want %q", got, "Default")
+	}
+}
+
+// TestNewExtension verifies the validation of extensions, both when built
+// directly and via the extend directive.
+func TestNewExtension(t *testing.T) {
+	rewrites := map[string]string{"content": "MyContent"}
+	ext, err := NewExtension("Base:layout", "MyLayout", rewrites)
+	if err != nil {
+		t.Fatalf("NewExtension failed: %v", err)
+	}
+	rewrites["content"] = "Other"
+	if ext.SourceTemplate != "Base:layout" || ext.DestTemplate != "MyLayout" || ext.Rewrites["content"] != "MyContent" {
+		t.Errorf("Unexpected extension: %+v", ext)
+	}
+
+	tests := []struct {
+		name, source, dest string
+		rewrites           map[string]string
+		errContains        string
+	}{
+		{"empty source", "", "MyLayout", nil, "non-empty source"},
+		{"empty dest", "Base:layout", "", nil, "non-empty destination"},
+		{"empty block", "Base:layout", "MyLayout", map[string]string{"": "x"}, "block and replacement must be non-empty"},
+		{"empty replacement", "Base:layout", "MyLayout", map[string]string{"content": ""}, "block and replacement must be non-empty"},
+		{"self reference", "Base:layout", "MyLayout", map[string]string{"content": "MyLayout"}, "would make MyLayout call itself"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewExtension(tt.source, tt.dest, tt.rewrites); err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"base.html": `{{ define "layout" }}{{ template "content" . }}{{ end }}`,
+		"page.html": `{{# namespace "Base" "base.html" #}}{{# extend "Base:layout" "MyLayout" "Base:content" "" #}}`,
+	})
+	templates, _ := group.Loader.Load("page.html", "")
+	if _, err := group.PreProcessHtmlTemplate(templates[0], nil); err == nil || !strings.Contains(err.Error(), "invalid rewrite") {
+		t.Errorf("Expected invalid rewrite error from directive, got %v", err)
+	}
+}
+
+// TestGroupExtend verifies that extensions added with Extend are applied on
+// every compile of the root, after (and on top of) its extend directives.
+func TestGroupExtend(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"base.html": `{{ define "layout" }}[{{ template "title" . }}|{{ template "content" . }}]{{ end }}{{ define "title" }}Title{{ end }}{{ define "content" }}Default{{ end }}`,
+		"page.html": `{{# namespace "Base" "base.html" #}}{{# extend "Base:layout" "PageLayout" "Base:title" "pageTitle" #}}` +
+			`{{ define "pageTitle" }}Page{{ end }}{{ define "tenantContent" }}Tenant{{ end }}`,
+	})
+	templates, _ := group.Loader.Load("page.html", "")
+	root := templates[0]
+
+	if err := group.Extend(root, "PageLayout", "TenantLayout", map[string]string{"Base:content": "tenantContent"}); err != nil {
+		t.Fatalf("Extend failed: %v", err)
+	}
+	for range 2 {
+		var buf bytes.Buffer
+		if err := group.RenderHtmlTemplate(&buf, root, "TenantLayout", nil, nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if got, want := buf.String(), "[Page|Tenant]"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	if n := len(root.AppliedExtensions()); n != 2 {
+		t.Errorf("Expected 2 applied extensions, got %d", n)
+	}
+
+	if err := group.Extend(root, "", "Other", nil); err == nil {
+		t.Error("Expected error for invalid extension")
+	}
+}
+
+// TestNamespace_ShadowedGlobal verifies that a "::" reference to a global
+// template the namespaced file also defines still resolves to the global one,
+// and fails under StrictGlobalRefs.
+func TestNamespace_ShadowedGlobal(t *testing.T) {
+	files := map[string]string{
+		"shared.html": `{{ define "formatDate" }}global{{ end }}`,
+		"ui.html":     `{{ define "formatDate" }}local{{ end }}{{ define "card" }}{{ template "::formatDate" . }}/{{ template "formatDate" . }}{{ end }}`,
+		"page.html":   `{{# include "shared.html" #}}{{# namespace "UI" "ui.html" #}}{{ define "page" }}{{ template "UI:card" . }}{{ end }}`,
+	}
+	if got, want := loadAndRender(t, files, "page.html", "page", nil), "global/local"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	group := NewTemplateGroup()
+	group.StrictGlobalRefs = true
+	group.Loader = NewMapLoader(files)
+	templates, _ := group.Loader.Load("page.html", "")
+	_, err := group.PreProcessHtmlTemplate(templates[0], nil)
+	var shadowed *ShadowedGlobalError
+	if !errors.As(err, &shadowed) || shadowed.Name != "formatDate" || shadowed.Namespace != "UI" || shadowed.Path != "ui.html" {
+		t.Errorf("Expected ShadowedGlobalError for formatDate, got %v", err)
+	}
+
+	// Global references to names the file does not define are fine
+	files["ui.html"] = `{{ define "card" }}{{ template "::formatDate" . }}{{ end }}`
+	group.Loader = NewMapLoader(files)
+	templates, _ = group.Loader.Load("page.html", "")
+	if _, err := group.PreProcessHtmlTemplate(templates[0], nil); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// TestNamespace_ShakenImports verifies that the templates kept and dropped by
+// tree-shaking are reported per import.
+func TestNamespace_ShakenImports(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"components.html": `{{ define "used1" }}U1{{ end }}{{ define "used2" }}{{ template "used3" }}{{ end }}{{ define "used3" }}U3{{ end }}{{ define "unused1" }}{{ end }}{{ define "unused2" }}{{ end }}`,
+		"widgets.html":    `{{ define "w1" }}W1{{ end }}{{ define "w2" }}W2{{ end }}`,
+		"all.html":        `{{ define "all" }}A{{ end }}`,
+		"page.html":       `{{# namespace "C" "components.html" "used1" "used2" #}}{{# include "widgets.html" "w1" #}}{{# namespace "A" "all.html" #}}`,
+	})
+	templates, _ := group.Loader.Load("page.html", "")
+	if _, err := group.PreProcessHtmlTemplate(templates[0], nil); err != nil {
+		t.Fatalf("PreProcess failed: %v", err)
+	}
+
+	want := []ShakenImport{
+		{Path: "components.html", Namespace: "C", EntryPoints: []string{"used1", "used2"}, Kept: []string{"used1", "used2", "used3"}, Dropped: []string{"unused1", "unused2"}},
+		{Path: "widgets.html", EntryPoints: []string{"w1"}, Kept: []string{"w1"}, Dropped: []string{"w2"}},
+	}
+	if got := templates[0].ShakenImports(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// TestNamespace_InheritedThroughIncludes verifies that files included (at any
+// depth) by a namespaced file are namespaced with it, while include_ns (like
+// namespace) puts a file into its own namespace.
+func TestNamespace_InheritedThroughIncludes(t *testing.T) {
+	files := map[string]string{
+		"page.html": `{{# namespace "UI" "ui.html" #}}` +
+			`{{ define "page" }}{{ template "UI:card" . }}|{{ template "UI:icon" . }}|{{ template "Icons:star" . }}{{ end }}`,
+		"ui.html": `{{# include "parts.html" #}}{{# include_ns "Icons" "icons.html" #}}` +
+			`{{ define "card" }}card({{ template "frame" . }}, {{ template "Icons:star" . }}){{ end }}`,
+		"parts.html": `{{# include "deep.html" #}}{{ define "frame" }}frame+{{ template "icon" . }}{{ end }}`,
+		"deep.html":  `{{ define "icon" }}icon{{ end }}`,
+		"icons.html": `{{ define "star" }}star{{ end }}`,
+	}
+	got := loadAndRender(t, files, "page.html", "page", nil)
+	if want := "card(frame+icon, star)|icon|star"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestIncludeNS verifies that include_ns behaves like namespace, including
+// taking entry points.
+func TestIncludeNS(t *testing.T) {
+	files := map[string]string{
+		"page.html": `{{# include_ns "UI" "ui.html" "button" #}}` +
+			`{{ define "page" }}{{ template "UI:button" . }}{{ end }}`,
+		"ui.html": `{{ define "button" }}[{{ template "label" . }}]{{ end }}{{ define "label" }}OK{{ end }}{{ define "card" }}card{{ end }}`,
+	}
+	got := loadAndRender(t, files, "page.html", "page", nil)
+	if want := "[OK]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestNamespace_Glob tests that a glob namespace import names templates in
+// subdirectories by their path, so files defining the same names do not
+// collide.
+func TestNamespace_Glob(t *testing.T) {
+	files := map[string]string{
+		"components/card.html":         `{{ define "card" }}[card {{ template "UI:forms/button" . }}]{{ end }}`,
+		"components/forms/button.html": `{{ define "button" }}<button>{{ template "label" . }}</button>{{ end }}{{ define "label" }}{{ . }}{{ end }}`,
+		"components/forms/input.html":  `{{# include "components/forms/hint.tmpl" #}}{{ define "input" }}<input>{{ tryTemplate "hint" . }}{{ end }}`,
+		"components/forms/hint.tmpl":   `{{ define "hint" }}({{ . }}){{ end }}`,
+		"components/nav/button.html":   `{{ define "button" }}<a>{{ . }}</a>{{ end }}`,
+		"page.html":                    `{{# namespace "UI" "components/**/*.html" #}}{{ template "UI:card" "x" }}|{{ template "UI:nav/button" "y" }}|{{ template "UI:forms/input" "z" }}`,
+		"nested.html":                  `{{# namespace "UI" "components/*/*.html" "button" #}}{{ template "UI:forms/button" "x" }}{{ template "UI:nav/button" "y" }}`,
+		"flat.html":                    `{{# namespace "UI" "components/*.html" #}}{{ template "UI:card" "x" }}`,
+		"none.html":                    `{{# namespace "UI" "widgets/*.html" #}}`,
+	}
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(files)
+
+	if got, want := renderWith(t, group, "page.html", nil), "[card <button>x</button>]|<a>y</a>|<input>(z)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := renderWith(t, group, "nested.html", nil), "<button>x</button><a>y</a>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Files in the pattern's base directory keep flat names, and a
+	// subdirectory's templates are only included if the pattern matches them
+	templates, _ := group.Loader.Load("flat.html", "")
+	if err := group.RenderHtmlTemplate(&bytes.Buffer{}, templates[0], "", nil, nil); err == nil {
+		t.Error("expected an error for UI:forms/button, which components/*.html does not import")
+	}
+
+	templates, _ = group.Loader.Load("none.html", "")
+	if err := group.RenderHtmlTemplate(&bytes.Buffer{}, templates[0], "", nil, nil); !errors.Is(err, TemplateNotFound) {
+		t.Errorf("expected TemplateNotFound for a glob without matches, got %v", err)
+	}
+
+	// Relative globs are rejected rather than matched against the loader's
+	// folders
+	group.Loader = NewMapLoader(map[string]string{"rel.html": `{{# namespace "UI" "./components/*.html" #}}`})
+	templates, _ = group.Loader.Load("rel.html", "")
+	if err := group.RenderHtmlTemplate(&bytes.Buffer{}, templates[0], "", nil, nil); err == nil || !strings.Contains(err.Error(), "must not be relative") {
+		t.Errorf("expected an error for a relative glob, got %v", err)
+	}
+
+	// Globs need a loader that can list its templates
+	group.Loader = struct{ TemplateLoader }{NewMapLoader(files)}
+	templates, _ = group.Loader.Load("page.html", "")
+	if err := group.RenderHtmlTemplate(&bytes.Buffer{}, templates[0], "", nil, nil); err == nil || !strings.Contains(err.Error(), "Lister") {
+		t.Errorf("expected an error for a loader without List, got %v", err)
+	}
+}
+
+// TestNamespace_GlobWrappedLoaders tests that glob imports work through the
+// loaders the group wraps its own in: with an Observer set and with
+// per-request overrides, and that a wrapped loader which cannot list still
+// reports so.
+func TestNamespace_GlobWrappedLoaders(t *testing.T) {
+	files := map[string]string{
+		"components/button.html": `{{ define "button" }}BTN{{ end }}`,
+		"page.html":              `{{# namespace "UI" "components/*.html" #}}{{ template "UI:button" }}`,
+	}
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(files)
+	group.Observer = &recordingObserver{}
+	if got := renderWith(t, group, "page.html", nil); got != "BTN" {
+		t.Errorf("with an Observer got %q, want BTN", got)
+	}
+
+	overrides := NewMapLoader(map[string]string{"components/button.html": `{{ define "button" }}TENANT{{ end }}`})
+	ctx := WithTemplateOverrides(context.Background(), overrides)
+	templates, err := group.LoaderFor(ctx).Load("page.html", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := group.RenderHtmlTemplateContext(ctx, &buf, templates[0], "", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "TENANT" {
+		t.Errorf("with overrides got %q, want TENANT", buf.String())
+	}
+
+	// Wrapping a loader that cannot list must not hide that from globs
+	group.Loader = struct{ TemplateLoader }{NewMapLoader(files)}
+	templates, err = group.Loader.Load("page.html", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = group.PreProcessHtmlTemplate(templates[0], nil)
+	if err == nil || !strings.Contains(err.Error(), "requires a loader that can list its templates") {
+		t.Errorf("with an Observer over a loader that cannot list, expected a Lister error, got %v", err)
+	}
+}
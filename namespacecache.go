@@ -0,0 +1,76 @@
+package templar
+
+import (
+	"fmt"
+	"slices"
+	"text/template/parse"
+)
+
+// cachedNamespace holds the trees of a namespaced import after tree-shaking
+// and rewriting, so other roots importing the same file into the same
+// namespace with the same entry points reuse them instead of parsing and
+// rewriting the file again.  The trees must be copied before being added
+// to a template, as html/template modifies the trees it escapes.
+type cachedNamespace struct {
+	// source and classPrefix are what the trees were built from.  A cached
+	// entry is only used if both still match, so edited files (or a
+	// ScopedClassPrefix that changed) are rewritten again.
+	source      string
+	classPrefix string
+
+	// trees are the rewritten trees keyed by their namespaced names.
+	trees map[string]*parse.Tree
+
+	// shaken records what tree-shaking kept and dropped.
+	shaken ShakenImport
+
+	// shadowed are the "::" references to global templates the file also
+	// defines (see shadowedGlobalRefs).
+	shadowed []string
+}
+
+// namespaceCacheKey returns the key a namespaced import is cached under:
+// its path, namespace (and path within it) and entry points.
+func namespaceCacheKey(curr *Template) string {
+	return fmt.Sprintf("namespace %q %q %q %q", curr.Namespace, curr.NamespacePath, curr.Path, curr.NamespaceEntryPoints)
+}
+
+// cachedNamespace returns the cached rewrite of curr if it was built from
+// its current source with classPrefix.
+func (t *TemplateGroup) cachedNamespace(curr *Template, classPrefix string) (*cachedNamespace, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cached, ok := t.namespaceCache[namespaceCacheKey(curr)]
+	if !ok || cached.source != curr.ParsedSource || cached.classPrefix != classPrefix {
+		return nil, false
+	}
+	return cached, true
+}
+
+// cacheNamespace caches the rewrite of a namespaced import.  Templates
+// without a Path (eg built from strings) are not cached.
+func (t *TemplateGroup) cacheNamespace(curr *Template, cached *cachedNamespace) {
+	if curr.Path == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.namespaceCache[namespaceCacheKey(curr)] = cached
+}
+
+// clearNamespaceCache drops all cached namespace rewrites, eg when the
+// funcs of a namespace change (which changes how its trees are rewritten).
+func (t *TemplateGroup) clearNamespaceCache() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.namespaceCache)
+}
+
+// shakenImport returns a copy of the ShakenImport recorded for the rewrite.
+func (c *cachedNamespace) shakenImport() ShakenImport {
+	shaken := c.shaken
+	shaken.EntryPoints = slices.Clone(shaken.EntryPoints)
+	shaken.Kept = slices.Clone(shaken.Kept)
+	shaken.Dropped = slices.Clone(shaken.Dropped)
+	return shaken
+}
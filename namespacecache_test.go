@@ -0,0 +1,78 @@
+package templar
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestNamespaceCache verifies that a namespaced import is rewritten once and
+// reused by other roots importing it the same way, while imports with other
+// entry points, into other namespaces or of an edited file are rewritten.
+func TestNamespaceCache(t *testing.T) {
+	observer := &recordingObserver{}
+	files := map[string]string{
+		"a.html":  `{{# namespace "UI" "ui.html" #}}{{ define "a" }}{{ template "UI:card" . }}{{ end }}`,
+		"b.html":  `{{# namespace "UI" "ui.html" #}}{{ define "b" }}{{ template "UI:button" . }}{{ end }}`,
+		"c.html":  `{{# namespace "UI" "ui.html" "button" #}}{{ define "c" }}{{ template "UI:button" . }}{{ end }}`,
+		"d.html":  `{{# namespace "Kit" "ui.html" #}}{{ define "d" }}{{ template "Kit:card" . }}{{ end }}`,
+		"ui.html": `{{ define "button" }}<button>{{ . }}</button>{{ end }}{{ define "card" }}<div>{{ template "button" . }}</div>{{ end }}`,
+	}
+	group := NewTemplateGroup()
+	group.Observer = observer
+	group.Loader = NewMapLoader(files)
+
+	render := func(name, entry string) string {
+		t.Helper()
+		templates, _ := group.Loader.Load(name, "")
+		var buf strings.Builder
+		if err := group.RenderHtmlTemplate(&buf, templates[0], entry, "<b>", nil); err != nil {
+			t.Fatalf("Render %s failed: %v", name, err)
+		}
+		return buf.String()
+	}
+
+	for _, tt := range []struct{ name, entry, want string }{
+		{"a.html", "a", "<div><button>&lt;b&gt;</button></div>"},
+		{"a.html", "a", "<div><button>&lt;b&gt;</button></div>"},
+		{"b.html", "b", "<button>&lt;b&gt;</button>"},
+		{"c.html", "c", "<button>&lt;b&gt;</button>"},
+		{"d.html", "d", "<div><button>&lt;b&gt;</button></div>"},
+	} {
+		if got := render(tt.name, tt.entry); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	var hits, misses []string
+	for _, event := range observer.events {
+		if name, ok := strings.CutPrefix(event, "hit:namespace "); ok {
+			hits = append(hits, name)
+		} else if name, ok := strings.CutPrefix(event, "miss:namespace "); ok {
+			misses = append(misses, name)
+		}
+	}
+	wantMisses := []string{`"UI" "" "ui.html" []`, `"UI" "" "ui.html" ["button"]`, `"Kit" "" "ui.html" []`}
+	if !slices.Equal(misses, wantMisses) {
+		t.Errorf("misses = %v, want %v", misses, wantMisses)
+	}
+	if want := []string{`"UI" "" "ui.html" []`, `"UI" "" "ui.html" []`}; !slices.Equal(hits, want) {
+		t.Errorf("hits = %v, want %v", hits, want)
+	}
+
+	// Tree-shaking is recorded on hits too
+	templates, _ := group.Loader.Load("c.html", "")
+	if err := group.RenderHtmlTemplate(&strings.Builder{}, templates[0], "c", nil, nil); err != nil {
+		t.Fatalf("Render c.html failed: %v", err)
+	}
+	if shaken := templates[0].ShakenImports(); len(shaken) != 1 || !slices.Equal(shaken[0].Dropped, []string{"card"}) {
+		t.Errorf("Unexpected shaken imports: %+v", shaken)
+	}
+
+	// An edited file is rewritten again
+	files["ui.html"] = `{{ define "card" }}<section>{{ . }}</section>{{ end }}`
+	group.Loader = NewMapLoader(files)
+	if got, want := render("a.html", "a"), "<section>&lt;b&gt;</section>"; got != want {
+		t.Errorf("after edit: got %q, want %q", got, want)
+	}
+}
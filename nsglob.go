@@ -0,0 +1,109 @@
+package templar
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// isGlobPattern reports whether an imported file name is a glob pattern.
+func isGlobPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// loadGlob loads every template whose name (as listed by the loader, which
+// must implement Lister) matches pattern.  Patterns use path.Match syntax
+// per path segment, and a "**" segment matches any number of directories.
+//
+// Each template's NamespacePath is set to its directory relative to the
+// pattern's base directory (the segments before the first wildcard), so
+// files in subdirectories get hierarchical names when namespaced.
+//
+// Patterns are matched against the loader's listed names, which are relative
+// to its folders rather than to the importing template, so relative
+// patterns ("./" or "../") are rejected.
+func loadGlob(loader TemplateLoader, pattern string) ([]*Template, error) {
+	if strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../") {
+		return nil, fmt.Errorf("glob %s must not be relative: globs match names relative to the loader's folders, not the importing template", pattern)
+	}
+	lister, ok := loader.(Lister)
+	var names []string
+	var err error
+	if ok {
+		names, err = lister.List()
+	}
+	if !ok || errors.Is(err, ErrNotListable) {
+		return nil, fmt.Errorf("glob %s requires a loader that can list its templates (Lister)", pattern)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	patternParts := strings.Split(pattern, "/")
+	base := globBase(patternParts)
+	var out []*Template
+	for _, name := range names {
+		if !matchGlob(patternParts, strings.Split(name, "/")) {
+			continue
+		}
+		templates, err := loader.Load(name, "")
+		if err != nil {
+			return nil, err
+		}
+		dir := path.Dir(strings.TrimPrefix(name, base))
+		for _, tmpl := range templates {
+			if dir != "." {
+				tmpl.NamespacePath = dir + "/"
+			}
+			out = append(out, tmpl)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no templates match %s: %w", pattern, TemplateNotFound)
+	}
+	return out, nil
+}
+
+// globBase returns the directory (with a trailing "/") made of the pattern
+// segments before the first one with a wildcard.
+func globBase(patternParts []string) string {
+	var base strings.Builder
+	for _, part := range patternParts[:len(patternParts)-1] {
+		if isGlobPattern(part) {
+			break
+		}
+		base.WriteString(part + "/")
+	}
+	return base.String()
+}
+
+// matchGlob reports whether the segments of a name match those of a pattern.
+func matchGlob(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchGlob(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchGlob(pattern[1:], name[1:])
+}
+
+// namespacedName applies the namespace resolution rules (see TransformName)
+// to a name referenced in t, prefixing local names with t's NamespacePath
+// as well as its Namespace.
+func (t *Template) namespacedName(name string) string {
+	if t.NamespacePath != "" && !strings.Contains(name, ":") {
+		name = t.NamespacePath + name
+	}
+	return TransformName(name, t.Namespace)
+}
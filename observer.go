@@ -0,0 +1,102 @@
+package templar
+
+import "time"
+
+// Observer receives events from a TemplateGroup for metrics and tracing, eg
+// to bridge to Prometheus or OpenTelemetry without this package depending on
+// them.  Set it on TemplateGroup.Observer; when nil (the default) no events
+// are produced and nothing is timed.
+//
+// Methods may be called concurrently from concurrent renders.
+type Observer interface {
+	// OnLoad is called after the group's loader loads a template (by the
+	// name or pattern that was requested) while preprocessing.
+	OnLoad(name string, dur time.Duration, err error)
+
+	// OnRender is called after each Render*Template call with the entry
+	// rendered (or the root's path if no entry was given).
+	OnRender(name string, dur time.Duration, err error)
+
+	// OnCacheHit and OnCacheMiss are called for RenderCached lookups and
+	// namespaced imports (whose rewritten templates are cached), with the
+	// cache key.  Compiled templates are not reported: every render compiles
+	// its root afresh, so there are no hits to count.
+	OnCacheHit(name string)
+	OnCacheMiss(name string)
+}
+
+// NopObserver implements Observer by ignoring all events.  Embed it to
+// implement only the methods you need.
+type NopObserver struct{}
+
+func (NopObserver) OnLoad(name string, dur time.Duration, err error)   {}
+func (NopObserver) OnRender(name string, dur time.Duration, err error) {}
+func (NopObserver) OnCacheHit(name string)                             {}
+func (NopObserver) OnCacheMiss(name string)                            {}
+
+// observedLoader reports every load through a TemplateLoader to an Observer.
+type observedLoader struct {
+	TemplateLoader
+	observer Observer
+}
+
+func (l observedLoader) Load(name string, cwd string) ([]*Template, error) {
+	start := time.Now()
+	tmpls, err := l.TemplateLoader.Load(name, cwd)
+	l.observer.OnLoad(name, time.Since(start), err)
+	return tmpls, err
+}
+
+// List delegates to the wrapped loader, so glob imports work with an
+// Observer set.  It returns ErrNotListable if the wrapped loader does not
+// implement Lister.
+func (l observedLoader) List() ([]string, error) {
+	if lister, ok := l.TemplateLoader.(Lister); ok {
+		return lister.List()
+	}
+	return nil, ErrNotListable
+}
+
+// observeLoader wraps loader so its loads are reported to the group's
+// Observer, if any.
+func (t *TemplateGroup) observeLoader(loader TemplateLoader) TemplateLoader {
+	if t.Observer == nil || loader == nil {
+		return loader
+	}
+	return observedLoader{TemplateLoader: loader, observer: t.Observer}
+}
+
+// observeRender reports a render that started at start to the group's
+// Observer.  It is deferred by the Render methods with their named error.
+func (t *TemplateGroup) observeRender(start time.Time, root *Template, entry string, err *error) {
+	t.Observer.OnRender(renderName(root, entry), time.Since(start), *err)
+}
+
+// reportRenderError passes a failed render's error to the group's
+// OnRenderError.  It is deferred by the Render methods with their named
+// error.
+func (t *TemplateGroup) reportRenderError(root *Template, entry string, err *error) {
+	if *err != nil {
+		t.OnRenderError(renderName(root, entry), *err)
+	}
+}
+
+// renderName is the name a render is reported under: the entry rendered, or
+// the root's path if no entry was given.
+func renderName(root *Template, entry string) string {
+	if entry == "" {
+		return root.sourceName()
+	}
+	return entry
+}
+
+// observeCache reports a cache hit or miss to the group's Observer, if any.
+func (t *TemplateGroup) observeCache(name string, hit bool) {
+	switch {
+	case t.Observer == nil:
+	case hit:
+		t.Observer.OnCacheHit(name)
+	default:
+		t.Observer.OnCacheMiss(name)
+	}
+}
@@ -0,0 +1,121 @@
+package templar
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingObserver records the events it receives.
+type recordingObserver struct {
+	NopObserver
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) record(event string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+}
+
+func (o *recordingObserver) OnLoad(name string, dur time.Duration, err error) {
+	o.record("load:" + name)
+}
+
+func (o *recordingObserver) OnRender(name string, dur time.Duration, err error) {
+	if err != nil {
+		name += ":error"
+	}
+	o.record("render:" + name)
+}
+
+func (o *recordingObserver) OnCacheHit(name string)  { o.record("hit:" + name) }
+func (o *recordingObserver) OnCacheMiss(name string) { o.record("miss:" + name) }
+
+// TestObserver verifies that loads, renders (including failed ones) and
+// render cache lookups are reported to the group's Observer.
+func TestObserver(t *testing.T) {
+	observer := &recordingObserver{}
+	group := NewTemplateGroup()
+	group.Observer = observer
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":   `{{# include "header.html" #}}{{ template "header" . }}`,
+		"header.html": `{{ define "header" }}H{{ end }}`,
+	})
+
+	renderWith(t, group, "page.html", nil)
+	templates, _ := group.Loader.Load("page.html", "")
+	var buf bytes.Buffer
+	group.RenderHtmlTemplate(&buf, templates[0], "missing", nil, nil)
+	for range 2 {
+		group.RenderCached("key", "v1", func() (string, error) { return "x", nil })
+	}
+
+	want := []string{
+		"load:header.html", "render:page.html",
+		"load:header.html", "render:missing:error",
+		"miss:key", "hit:key",
+	}
+	if !reflect.DeepEqual(observer.events, want) {
+		t.Errorf("got events %v, want %v", observer.events, want)
+	}
+}
+
+// TestObserver_NamespaceCache verifies that a namespaced import is reported
+// as a miss on the first render and a hit on the second, while compiled
+// templates produce no cache events.
+func TestObserver_NamespaceCache(t *testing.T) {
+	observer := &recordingObserver{}
+	group := NewTemplateGroup()
+	group.Observer = observer
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": `{{# namespace "UI" "ui.html" #}}{{ template "UI:button" . }}`,
+		"ui.html":   `{{ define "button" }}B{{ end }}`,
+	})
+
+	for range 2 {
+		renderWith(t, group, "page.html", nil)
+	}
+	var cacheEvents []string
+	for _, event := range observer.events {
+		if strings.HasPrefix(event, "hit:") || strings.HasPrefix(event, "miss:") {
+			cacheEvents = append(cacheEvents, event)
+		}
+	}
+	key := `namespace "UI" "" "ui.html" []`
+	if want := []string{"miss:" + key, "hit:" + key}; !reflect.DeepEqual(cacheEvents, want) {
+		t.Errorf("got cache events %q, want %q", cacheEvents, want)
+	}
+}
+
+// TestOnRenderError verifies that failed html and text renders, and only
+// those, are passed to OnRenderError.
+func TestOnRenderError(t *testing.T) {
+	var reported []string
+	group := NewTemplateGroup()
+	group.OnRenderError = func(name string, err error) {
+		reported = append(reported, name+": "+err.Error())
+	}
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":   `{{ define "ok" }}fine{{ end }}`,
+		"broken.html": `{{ .Missing.Field }}`,
+	})
+
+	renderWith(t, group, "page.html", nil)
+	templates, _ := group.Loader.Load("page.html", "")
+	errHtml := group.Clone().RenderHtmlTemplate(&bytes.Buffer{}, templates[0], "missing", nil, nil)
+	templates, _ = group.Loader.Load("broken.html", "")
+	errText := group.RenderTextTemplate(&bytes.Buffer{}, templates[0], "", map[string]any{"Missing": 1}, nil)
+	if errHtml == nil || errText == nil {
+		t.Fatalf("expected both renders to fail, got %v and %v", errHtml, errText)
+	}
+
+	want := []string{"missing: " + errHtml.Error(), "broken.html: " + errText.Error()}
+	if !reflect.DeepEqual(reported, want) {
+		t.Errorf("got %v, want %v", reported, want)
+	}
+}
@@ -0,0 +1,86 @@
+package templar
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// OverrideLoader loads templates from Overrides, falling back to Base for
+// templates Overrides does not have, eg to let a tenant replace a few shared
+// templates (like header.html) in a white-label deployment.
+type OverrideLoader struct {
+	// Overrides is tried first.
+	Overrides TemplateLoader
+
+	// Base loads every template that is not overridden.
+	Base TemplateLoader
+}
+
+// Load loads name from Overrides, or from Base if Overrides returns
+// TemplateNotFound (or an error wrapping it).  Other errors from Overrides
+// are returned as is.
+func (o *OverrideLoader) Load(name string, cwd string) ([]*Template, error) {
+	templates, err := o.Overrides.Load(name, cwd)
+	if errors.Is(err, TemplateNotFound) || (err == nil && len(templates) == 0) {
+		return o.Base.Load(name, cwd)
+	}
+	return templates, err
+}
+
+// List returns the names listed by Overrides and Base (sorted, without
+// duplicates), skipping either if it cannot list (does not implement Lister
+// or returns ErrNotListable).
+func (o *OverrideLoader) List() ([]string, error) {
+	seen := make(map[string]bool)
+	for _, loader := range []TemplateLoader{o.Overrides, o.Base} {
+		lister, ok := loader.(Lister)
+		if !ok {
+			continue
+		}
+		names, err := lister.List()
+		if errors.Is(err, ErrNotListable) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			seen[name] = true
+		}
+	}
+	return sortedKeys(seen), nil
+}
+
+// overridesKey is the context key of the loader set by WithTemplateOverrides.
+type overridesKey struct{}
+
+// WithTemplateOverrides returns a copy of ctx that makes renders with the
+// context (see RenderHtmlTemplateContext) load templates from overrides
+// before the group's Loader, eg in a middleware picking the tenant of a
+// request:
+//
+//	tenantDir := templar.NewFileSystemLoader(templar.LocalFolder("tenants/" + tenant))
+//	r = r.WithContext(templar.WithTemplateOverrides(r.Context(), tenantDir))
+func WithTemplateOverrides(ctx context.Context, overrides TemplateLoader) context.Context {
+	return context.WithValue(ctx, overridesKey{}, overrides)
+}
+
+// LoaderFor returns the loader templates are loaded with for ctx: an
+// OverrideLoader over the group's Loader if ctx has overrides (see
+// WithTemplateOverrides), and the group's Loader otherwise.  Use it to load
+// the root of a render so the root can be overridden too.
+func (t *TemplateGroup) LoaderFor(ctx context.Context) TemplateLoader {
+	overrides, _ := ctx.Value(overridesKey{}).(TemplateLoader)
+	if overrides == nil {
+		return t.Loader
+	}
+	return &OverrideLoader{Overrides: overrides, Base: t.Loader}
+}
+
+// RenderHtmlTemplateContext is RenderHtmlTemplate, except that the files
+// root includes and its layouts are loaded with LoaderFor(ctx), so
+// per-request overrides apply without a group per tenant.
+func (t *TemplateGroup) RenderHtmlTemplateContext(ctx context.Context, w io.Writer, root *Template, entry string, data any, funcs map[string]any) error {
+	return t.renderHtml(w, root, entry, data, funcs, t.LoaderFor(ctx))
+}
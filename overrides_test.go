@@ -0,0 +1,72 @@
+package templar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestRenderHtmlTemplateContext verifies that overrides set on a context
+// replace included templates, layouts and the root for renders with that
+// context only.
+func TestRenderHtmlTemplateContext(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":   `{{# meta "layout" "base.html" #}}{{# include "header.html" #}}{{ template "header" . }}|body`,
+		"header.html": `{{ define "header" }}Shared header{{ end }}`,
+		"base.html":   `<main>{{ .Content }}</main>`,
+	})
+	tenant := NewMapLoader(map[string]string{
+		"header.html": `{{ define "header" }}Tenant header{{ end }}`,
+		"base.html":   `<div class="tenant">{{ .Content }}</div>`,
+	})
+
+	render := func(ctx context.Context) string {
+		t.Helper()
+		roots, err := group.LoaderFor(ctx).Load("page.html", "")
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := group.RenderHtmlTemplateContext(ctx, &buf, roots[0], "", nil, nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	ctx := WithTemplateOverrides(context.Background(), tenant)
+	if got, want := render(ctx), `<div class="tenant">Tenant header|body</div>`; got != want {
+		t.Errorf("with overrides: got %q, want %q", got, want)
+	}
+	if got, want := render(context.Background()), `<main>Shared header|body</main>`; got != want {
+		t.Errorf("without overrides: got %q, want %q", got, want)
+	}
+}
+
+// failingLoader fails every load with err.
+type failingLoader struct{ err error }
+
+func (f failingLoader) Load(name string, cwd string) ([]*Template, error) {
+	return nil, f.err
+}
+
+// TestOverrideLoader_WrappedNotFound verifies that OverrideLoader falls back
+// to Base when Overrides returns an error wrapping TemplateNotFound, and
+// returns other errors as is.
+func TestOverrideLoader_WrappedNotFound(t *testing.T) {
+	base := NewMapLoader(map[string]string{"header.html": "Shared"})
+
+	loader := &OverrideLoader{Overrides: failingLoader{fmt.Errorf("tenant header.html: %w", TemplateNotFound)}, Base: base}
+	templates, err := loader.Load("header.html", "")
+	if err != nil || len(templates) != 1 || string(templates[0].RawSource) != "Shared" {
+		t.Errorf("Expected to fall back to Base, got %v, %v", templates, err)
+	}
+
+	broken := errors.New("tenant store unavailable")
+	loader = &OverrideLoader{Overrides: failingLoader{broken}, Base: base}
+	if _, err := loader.Load("header.html", ""); err != broken {
+		t.Errorf("Expected the Overrides error, got %v", err)
+	}
+}
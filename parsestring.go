@@ -0,0 +1,37 @@
+package templar
+
+// ParseString compiles an in-memory template source (which may contain
+// include, namespace and other directives) against the group's Loader,
+// without needing the source to exist on disk.  This is handy for REPLs,
+// tests and templates stored in a database.
+//
+// The returned template is path-less so its includes are resolved only
+// against the loader's own search paths (see ParseStringIn to resolve
+// relative includes from a base directory).  It can be rendered with
+// RenderHtmlTemplate or RenderTextTemplate (matching asHtml).
+func (t *TemplateGroup) ParseString(name, source string, asHtml bool) (*Template, error) {
+	return t.ParseStringIn("", name, source, asHtml)
+}
+
+// ParseStringIn is like ParseString but resolves the template's relative
+// includes (e.g., "./partial.html") against baseDir, as if the source was a
+// file in that directory.
+func (t *TemplateGroup) ParseStringIn(baseDir, name, source string, asHtml bool) (*Template, error) {
+	root := &Template{
+		Name:      name,
+		RawSource: []byte(source),
+		AsHtml:    asHtml,
+		BaseDir:   baseDir,
+	}
+
+	var err error
+	if asHtml {
+		_, err = t.PreProcessHtmlTemplate(root, nil)
+	} else {
+		_, err = t.PreProcessTextTemplate(root, nil)
+	}
+	if err != nil {
+		return nil, panicOrError(err)
+	}
+	return root, nil
+}
@@ -0,0 +1,60 @@
+package templar
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestParseString verifies that a string template with includes is compiled
+// against the group's loader and can then be rendered.
+func TestParseString(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"greet.html": `{{ define "greet" }}Hello {{ . }}{{ end }}`,
+	})
+
+	root, err := group.ParseString("inline", `{{# include "greet.html" #}}{{ template "greet" .Name }}!`, true)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := group.RenderHtmlTemplate(&buf, root, "", map[string]any{"Name": "World"}, nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got, want := buf.String(), "Hello World!"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := group.ParseString("bad", `{{# include "missing.html" #}}`, true); err == nil {
+		t.Error("Expected error for missing include")
+	}
+	if _, err := group.ParseString("bad", `{{ if }}`, false); err == nil {
+		t.Error("Expected error for invalid syntax")
+	}
+}
+
+// TestParseStringIn verifies that relative includes in a path-less template
+// are resolved against its base directory.
+func TestParseStringIn(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"emails/footer.html": `{{ define "footer" }}Bye{{ end }}`,
+	})
+
+	if _, err := group.ParseString("inline", `{{# include "./footer.html" #}}`, true); err == nil {
+		t.Error("Expected relative include to fail without a base dir")
+	}
+
+	root, err := group.ParseStringIn("emails", "inline", `{{# include "./footer.html" #}}{{ template "footer" }}`, true)
+	if err != nil {
+		t.Fatalf("ParseStringIn failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := group.RenderHtmlTemplate(&buf, root, "", nil, nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got := buf.String(); got != "Bye" {
+		t.Errorf("got %q, want %q", got, "Bye")
+	}
+}
@@ -1,6 +1,7 @@
 package templar
 
 import (
+	"regexp"
 	"strings"
 	"text/template/parse"
 )
@@ -66,6 +67,107 @@ func WalkParseTree(node parse.Node, visitor func(*parse.TemplateNode)) {
 	}
 }
 
+// WalkNodes walks every node in a parse tree (including pipelines, commands and
+// their arguments) in depth-first order and calls visit for each one.
+// Unlike WalkParseTree, which only reports template calls, this reaches
+// function identifiers, fields and variables used inside actions.
+func WalkNodes(node parse.Node, visit func(parse.Node)) {
+	if node == nil {
+		return
+	}
+	visit(node)
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, child := range n.Nodes {
+				WalkNodes(child, visit)
+			}
+		}
+	case *parse.ActionNode:
+		walkPipe(n.Pipe, visit)
+	case *parse.TemplateNode:
+		walkPipe(n.Pipe, visit)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.PipeNode:
+		for _, v := range n.Decl {
+			WalkNodes(v, visit)
+		}
+		for _, cmd := range n.Cmds {
+			WalkNodes(cmd, visit)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			WalkNodes(arg, visit)
+		}
+	case *parse.ChainNode:
+		WalkNodes(n.Node, visit)
+	}
+}
+
+func walkPipe(pipe *parse.PipeNode, visit func(parse.Node)) {
+	if pipe != nil {
+		WalkNodes(pipe, visit)
+	}
+}
+
+func walkBranch(b *parse.BranchNode, visit func(parse.Node)) {
+	walkPipe(b.Pipe, visit)
+	if b.List != nil {
+		WalkNodes(b.List, visit)
+	}
+	if b.ElseList != nil {
+		WalkNodes(b.ElseList, visit)
+	}
+}
+
+// RenameFuncs rewrites function identifiers within a parse tree according to
+// the given mapping (old name → new name).  It modifies the tree in place.
+func RenameFuncs(tree *parse.Tree, renames map[string]string) {
+	if tree == nil || tree.Root == nil || len(renames) == 0 {
+		return
+	}
+	WalkNodes(tree.Root, func(node parse.Node) {
+		if ident, ok := node.(*parse.IdentifierNode); ok {
+			if newName, ok := renames[ident.Ident]; ok {
+				ident.Ident = newName
+			}
+		}
+	})
+}
+
+// classAttrRegex matches a class attribute in template text, up to its
+// closing quote or the end of the text (when an action follows, eg
+// class="btn {{ .Extra }}").
+var classAttrRegex = regexp.MustCompile(`(\sclass\s*=\s*["'])([^"']*)`)
+
+// classNameRegex matches a single class name within a class attribute.
+var classNameRegex = regexp.MustCompile(`[^\s]+`)
+
+// PrefixClasses adds prefix to every static class name in the class
+// attributes of a parse tree's text, eg class="btn primary" becomes
+// class="ui-btn ui-primary".  Class names produced by actions are left
+// alone.  It modifies the tree in place.
+func PrefixClasses(tree *parse.Tree, prefix string) {
+	if tree == nil || tree.Root == nil || prefix == "" {
+		return
+	}
+	WalkNodes(tree.Root, func(node parse.Node) {
+		if text, ok := node.(*parse.TextNode); ok {
+			text.Text = classAttrRegex.ReplaceAllFunc(text.Text, func(attr []byte) []byte {
+				m := classAttrRegex.FindSubmatchIndex(attr)
+				names := classNameRegex.ReplaceAll(attr[m[4]:m[5]], []byte(prefix+"$0"))
+				return append(append([]byte{}, attr[:m[4]]...), names...)
+			})
+		}
+	})
+}
+
 // ApplyNamespaceToTree applies a namespace transformation to all template
 // references within a parse tree. It modifies the tree in place.
 //
@@ -231,6 +333,30 @@ func ComputeReachableTemplates(templates map[string]*parse.Tree, entryPoints []s
 	return reachable
 }
 
+// ComputeNamespacedReachableTemplates is like ComputeReachableTemplates but also
+// reports the final name each reachable template will have once it is added
+// under the given namespace.  Entry points are the raw (pre-namespace) names.
+// If entryPoints is empty, every template is considered reachable.
+//
+// Returns: map of raw template name to its namespaced name (e.g., "button" → "UI:button")
+func ComputeNamespacedReachableTemplates(templates map[string]*parse.Tree, entryPoints []string, namespace string) map[string]string {
+	var reachable map[string]bool
+	if len(entryPoints) > 0 {
+		reachable = ComputeReachableTemplates(templates, entryPoints)
+	} else {
+		reachable = make(map[string]bool, len(templates))
+		for name := range templates {
+			reachable[name] = true
+		}
+	}
+
+	names := make(map[string]string, len(reachable))
+	for name := range reachable {
+		names[name] = TransformName(name, namespace)
+	}
+	return names
+}
+
 // CopyTreeWithRewrites creates a deep copy of a parse tree and rewrites
 // template references according to the provided mapping.
 //
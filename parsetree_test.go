@@ -420,3 +420,40 @@ func TestCopyTreeWithRewrites(t *testing.T) {
 		}
 	}
 }
+
+// TestComputeNamespacedReachableTemplates verifies that tree-shaking results
+// are reported with both the raw and the final namespaced names.
+func TestComputeNamespacedReachableTemplates(t *testing.T) {
+	source := `
+{{ define "button" }}{{ template "icon" . }}{{ template "::global" . }}{{ end }}
+{{ define "icon" }}Icon{{ end }}
+{{ define "unused" }}Unused{{ end }}
+`
+	tmpl, err := template.New("test").Parse(source)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	templates := make(map[string]*parse.Tree)
+	for _, t := range tmpl.Templates() {
+		if t.Name() != "test" && t.Tree != nil {
+			templates[t.Name()] = t.Tree
+		}
+	}
+
+	result := ComputeNamespacedReachableTemplates(templates, []string{"button"}, "UI")
+	expected := map[string]string{"button": "UI:button", "icon": "UI:icon"}
+	if len(result) != len(expected) {
+		t.Fatalf("Got %v, want %v", result, expected)
+	}
+	for raw, final := range expected {
+		if result[raw] != final {
+			t.Errorf("result[%q] = %q, want %q", raw, result[raw], final)
+		}
+	}
+
+	// Without entry points everything is included
+	all := ComputeNamespacedReachableTemplates(templates, nil, "UI")
+	if len(all) != 3 || all["unused"] != "UI:unused" {
+		t.Errorf("Expected all templates to be namespaced, got %v", all)
+	}
+}
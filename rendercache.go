@@ -0,0 +1,40 @@
+package templar
+
+// cachedRender is a rendered output along with the version it was rendered for.
+type cachedRender struct {
+	version string
+	output  string
+}
+
+// RenderCached returns the output cached under key if it was rendered for the
+// same version.  Otherwise it calls render, caches its output under key with
+// the new version, and returns it.  Failed renders are not cached.
+//
+// The caller controls both key and version, e.g. a product card keyed by the
+// product ID and versioned by its updated_at timestamp:
+//
+//	html, err := group.RenderCached("product-card:"+p.ID, p.UpdatedAt.String(), func() (string, error) {
+//		var buf bytes.Buffer
+//		err := group.RenderHtmlTemplate(&buf, card, "ProductCard", p, nil)
+//		return buf.String(), err
+//	})
+func (t *TemplateGroup) RenderCached(key string, version string, render func() (string, error)) (string, error) {
+	t.mu.Lock()
+	cached, ok := t.renderCache[key]
+	t.mu.Unlock()
+	hit := ok && cached.version == version
+	t.observeCache(key, hit)
+	if hit {
+		return cached.output, nil
+	}
+
+	output, err := render()
+	if err != nil {
+		return output, err
+	}
+
+	t.mu.Lock()
+	t.renderCache[key] = cachedRender{version: version, output: output}
+	t.mu.Unlock()
+	return output, nil
+}
@@ -0,0 +1,39 @@
+package templar
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestRenderCached verifies that cached output is reused for the same version,
+// re-rendered when the version changes, and that failures are not cached.
+func TestRenderCached(t *testing.T) {
+	group := NewTemplateGroup()
+	calls := 0
+	render := func(out string) func() (string, error) {
+		return func() (string, error) {
+			calls++
+			return out, nil
+		}
+	}
+
+	got, _ := group.RenderCached("card:1", "v1", render("first"))
+	got2, _ := group.RenderCached("card:1", "v1", render("ignored"))
+	if got != "first" || got2 != "first" || calls != 1 {
+		t.Errorf("Expected cached render, got %q, %q after %d calls", got, got2, calls)
+	}
+
+	got, _ = group.RenderCached("card:1", "v2", render("second"))
+	if got != "second" || calls != 2 {
+		t.Errorf("Expected re-render for new version, got %q after %d calls", got, calls)
+	}
+
+	failing := func() (string, error) { calls++; return "", errors.New("boom") }
+	if _, err := group.RenderCached("card:2", "v1", failing); err == nil {
+		t.Error("Expected render error")
+	}
+	got, _ = group.RenderCached("card:2", "v1", render("ok"))
+	if got != "ok" {
+		t.Errorf("Failed render should not be cached, got %q", got)
+	}
+}
@@ -0,0 +1,64 @@
+package templar
+
+import (
+	"bytes"
+	"maps"
+	"time"
+)
+
+// RenderOutput is the result of RenderResult: the rendered output along with
+// what a caller typically needs after a render, eg a static site generator
+// writing Body to a file and using Metadata to build an index.
+type RenderOutput struct {
+	// Body is the rendered html.
+	Body []byte
+
+	// Metadata is a copy of the root's Metadata (front matter and meta
+	// directives) after preprocessing.
+	Metadata map[string]any
+
+	// Dependencies are the paths (or names) of the templates root pulled in,
+	// directly or transitively, in the order they were first included.  The
+	// root itself is not included.
+	Dependencies []string
+
+	// Duration is how long the render took, including preprocessing.
+	Duration time.Duration
+}
+
+// RenderResult renders root as html like RenderHtmlTemplate and returns the
+// output along with the root's metadata and dependencies.
+func (t *TemplateGroup) RenderResult(root *Template, entry string, data any, funcs map[string]any) (*RenderOutput, error) {
+	start := time.Now()
+	var buf bytes.Buffer
+	if err := t.RenderHtmlTemplate(&buf, root, entry, data, funcs); err != nil {
+		return nil, err
+	}
+	return &RenderOutput{
+		Body:         buf.Bytes(),
+		Metadata:     maps.Clone(root.Metadata),
+		Dependencies: transitiveDependencies(root),
+		Duration:     time.Since(start),
+	}, nil
+}
+
+// transitiveDependencies returns the source names of the templates root
+// depends on, directly or transitively, in depth-first order.
+func transitiveDependencies(root *Template) []string {
+	seen := map[string]bool{root.sourceName(): true}
+	var deps []string
+	var visit func(tmpl *Template)
+	visit = func(tmpl *Template) {
+		for _, dep := range tmpl.Dependencies() {
+			name := dep.sourceName()
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			deps = append(deps, name)
+			visit(dep)
+		}
+	}
+	visit(root)
+	return deps
+}
@@ -0,0 +1,42 @@
+package templar
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestRenderResult verifies that RenderResult returns the body along with
+// the root's metadata and its transitive dependencies.
+func TestRenderResult(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":   `{{# meta "title" "Home" #}}{{# include "layout.html" #}}{{# namespace "UI" "ui.html" #}}{{ template "layout" . }}`,
+		"layout.html": `{{# include "nav.html" #}}{{ define "layout" }}<main>{{ template "nav" . }}{{ template "UI:button" . }}</main>{{ end }}`,
+		"nav.html":    `{{ define "nav" }}<nav>{{ .Name }}</nav>{{ end }}`,
+		"ui.html":     `{{ define "button" }}<button>Go</button>{{ end }}`,
+	})
+	templates, _ := group.Loader.Load("page.html", "")
+
+	for range 2 {
+		out, err := group.RenderResult(templates[0], "", map[string]any{"Name": "Ann"}, nil)
+		if err != nil {
+			t.Fatalf("RenderResult failed: %v", err)
+		}
+		if got, want := string(out.Body), "<main><nav>Ann</nav><button>Go</button></main>"; got != want {
+			t.Errorf("Body = %q, want %q", got, want)
+		}
+		if out.Metadata["title"] != "Home" {
+			t.Errorf("Metadata = %v, want title Home", out.Metadata)
+		}
+		if want := []string{"layout.html", "nav.html", "ui.html"}; !slices.Equal(out.Dependencies, want) {
+			t.Errorf("Dependencies = %v, want %v", out.Dependencies, want)
+		}
+		if out.Duration <= 0 {
+			t.Errorf("Expected a positive Duration, got %v", out.Duration)
+		}
+	}
+
+	if _, err := group.RenderResult(templates[0], "missing", nil, nil); err == nil {
+		t.Error("Expected error for missing entry")
+	}
+}
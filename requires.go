@@ -0,0 +1,104 @@
+package templar
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MissingDataError is returned when a template declares a required data key
+// (via the requires directive) that is missing or nil in the render data.
+type MissingDataError struct {
+	// Template is the path (or name) of the template that declared the key.
+	Template string
+
+	// Key is the dotted path of the missing key (e.g., "User.Name").
+	Key string
+}
+
+func (e *MissingDataError) Error() string {
+	return fmt.Sprintf("template %s requires .%s but it is missing or nil", e.Template, e.Key)
+}
+
+// requirement is a required data key along with the template that declared it.
+type requirement struct {
+	template string
+	key      string
+}
+
+// collectRequires records the keys declared by a processed template onto the
+// root so they can be verified before rendering.  root.requirements must be
+// reset before walking.
+func collectRequires(root *Template, curr *Template) {
+	for _, key := range curr.Requires {
+		root.requirements = append(root.requirements, requirement{template: curr.sourceName(), key: key})
+	}
+}
+
+// checkRequires verifies that every key required by root or any of the
+// templates it pulled in is present in data.
+func checkRequires(root *Template, data any) error {
+	for _, req := range root.requirements {
+		if !hasPath(req.key, data) {
+			return &MissingDataError{Template: req.template, Key: req.key}
+		}
+	}
+	return nil
+}
+
+// hasPath reports whether the dotted path resolves to a non-nil value in data.
+func hasPath(path string, data any) bool {
+	v, ok := lookupPath(data, path)
+	return ok && !isNilValue(v)
+}
+
+// lookupPath resolves a dotted path (e.g., "User.Name") against data, walking
+// through maps with string keys, struct fields and pointers.  A leading "."
+// is allowed.  Returns false if any segment is missing.
+func lookupPath(data any, path string) (reflect.Value, bool) {
+	v := reflect.ValueOf(data)
+	for _, part := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return v, false
+			}
+			v = v.MapIndex(reflect.ValueOf(part).Convert(v.Type().Key()))
+		case reflect.Struct:
+			field, ok := v.Type().FieldByName(part)
+			if !ok || !field.IsExported() {
+				return reflect.Value{}, false
+			}
+			// A promoted field behind a nil embedded pointer is missing
+			fv, err := v.FieldByIndexErr(field.Index)
+			if err != nil {
+				return reflect.Value{}, false
+			}
+			v = fv
+		default:
+			return reflect.Value{}, false
+		}
+		if !v.IsValid() {
+			return v, false
+		}
+	}
+	return v, true
+}
+
+// isNilValue reports whether v is invalid or a nil pointer, interface, map, slice, func or chan.
+func isNilValue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package templar
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestRequires verifies that keys declared via the requires directive (in the
+// root or an included template) are checked against the render data.
+func TestRequires(t *testing.T) {
+	type User struct{ Name string }
+	type Page struct{ Title string }
+	type Embedded struct {
+		*Page
+		User *User
+	}
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html":   `{{# requires "Title" #}}{{# include "header.html" #}}{{ .Title }}`,
+		"header.html": `{{# requires "User.Name" #}}{{ define "header" }}{{ .User.Name }}{{ end }}`,
+	})
+	templates, _ := group.Loader.Load("page.html", "")
+
+	tests := []struct {
+		name    string
+		data    any
+		missing string
+	}{
+		{"all present", map[string]any{"Title": "T", "User": &User{Name: "Ann"}}, ""},
+		{"missing title", map[string]any{"User": User{Name: "Ann"}}, "Title"},
+		{"nil user", map[string]any{"Title": "T", "User": (*User)(nil)}, "User.Name"},
+		{"struct data", struct {
+			Title string
+			User  map[string]string
+		}{"T", map[string]string{"Name": "Ann"}}, ""},
+		{"embedded", Embedded{&Page{Title: "T"}, &User{Name: "Ann"}}, ""},
+		{"nil embedded", Embedded{nil, &User{Name: "Ann"}}, "Title"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := group.RenderHtmlTemplate(&buf, templates[0], "", tt.data, nil)
+			var missing *MissingDataError
+			if tt.missing == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &missing) || missing.Key != tt.missing {
+				t.Errorf("Expected MissingDataError for %q, got %v", tt.missing, err)
+			}
+		})
+	}
+}
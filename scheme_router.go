@@ -0,0 +1,93 @@
+package templar
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SchemeRouter is a loader that routes each name to a loader registered for
+// its URL scheme, eg http://... to a remote loader and s3://... to a bucket
+// loader, and scheme-less names (including @source/... names when Default is
+// a SourceLoader) to Default.  This lets templates mix local, remote and
+// vendored templates transparently:
+//
+//	router := templar.NewSchemeRouter(sourceLoader).
+//		Register("https", httpLoader).
+//		Register("s3", s3Loader)
+//	group.Loader = router
+//
+// Scheme loaders receive the full name, including its scheme.  Relative
+// names ("./x", "../x") included from a template that was loaded via a scheme
+// are resolved against that template's URL, so they reach the same loader.
+type SchemeRouter struct {
+	// Default loads names without a scheme.  If nil, such names are not found.
+	Default TemplateLoader
+
+	loaders map[string]TemplateLoader
+}
+
+// NewSchemeRouter creates a router that loads scheme-less names with def.
+func NewSchemeRouter(def TemplateLoader) *SchemeRouter {
+	return &SchemeRouter{Default: def, loaders: make(map[string]TemplateLoader)}
+}
+
+// Register routes names with the given scheme (without "://", eg "https")
+// to loader.  Returns the router for method chaining.
+func (r *SchemeRouter) Register(scheme string, loader TemplateLoader) *SchemeRouter {
+	if r.loaders == nil {
+		r.loaders = make(map[string]TemplateLoader)
+	}
+	r.loaders[strings.ToLower(scheme)] = loader
+	return r
+}
+
+// Load loads name with the loader registered for its scheme, or with Default
+// if it has none.
+func (r *SchemeRouter) Load(name string, cwd string) ([]*Template, error) {
+	if strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../") {
+		// Template dirs of URLs lose a slash (eg https:/host/dir), so
+		// resolve against the URL here rather than in the scheme loader
+		if scheme, rest, ok := cutScheme(cwd); ok && r.loaders[scheme] != nil {
+			name, cwd = scheme+"://"+path.Join(strings.TrimLeft(rest, "/"), name), ""
+		}
+	}
+
+	if scheme, _, ok := cutScheme(name); ok {
+		loader := r.loaders[scheme]
+		if loader == nil {
+			return nil, panicOrError(fmt.Errorf("no loader registered for scheme '%s' (loading %s)", scheme, name))
+		}
+		return loader.Load(name, cwd)
+	}
+	if r.Default == nil {
+		return nil, TemplateNotFound
+	}
+	return r.Default.Load(name, cwd)
+}
+
+// List delegates to Default if it implements Lister.  Templates behind
+// scheme loaders are not listed.
+func (r *SchemeRouter) List() ([]string, error) {
+	if lister, ok := r.Default.(Lister); ok {
+		return lister.List()
+	}
+	return nil, nil
+}
+
+// cutScheme splits a URL like scheme://rest (or scheme:/rest, as left by
+// filepath.Dir) into its lower cased scheme and the rest.  Single letter
+// schemes are rejected so Windows drive letters are not mistaken for them.
+func cutScheme(name string) (scheme string, rest string, ok bool) {
+	i := strings.Index(name, ":/")
+	if i < 2 {
+		return "", name, false
+	}
+	for j, c := range name[:i] {
+		isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !isLetter && (j == 0 || !(c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.')) {
+			return "", name, false
+		}
+	}
+	return strings.ToLower(name[:i]), name[i+1:], true
+}
@@ -0,0 +1,33 @@
+package templar
+
+import (
+	"testing"
+)
+
+// TestSchemeRouter verifies that names are routed to the loader registered
+// for their scheme, that relative includes from a scheme loaded template stay
+// on its loader, and that scheme-less names use the default loader.
+func TestSchemeRouter(t *testing.T) {
+	remote := NewMapLoader(map[string]string{
+		"mem://ui/button.html": `{{# include "./icon.html" #}}{{ define "button" }}[{{ template "icon" }}]{{ end }}`,
+		"mem://ui/icon.html":   `{{ define "icon" }}*{{ end }}`,
+	})
+	local := NewMapLoader(map[string]string{
+		"page.html": `{{# include "mem://ui/button.html" #}}{{ template "button" }}`,
+		"bad.html":  `{{# include "ftp://x/y.html" #}}`,
+	})
+	group := NewTemplateGroup()
+	group.Loader = NewSchemeRouter(local).Register("MEM", remote)
+
+	if got, want := renderWith(t, group, "page.html", nil), "[*]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	templates, _ := group.Loader.Load("bad.html", "")
+	if _, err := group.PreProcessHtmlTemplate(templates[0], nil); err == nil {
+		t.Error("Expected an error for an unregistered scheme")
+	}
+	if _, err := group.Loader.Load("C:/templates/page.html", ""); err != TemplateNotFound {
+		t.Errorf("Expected drive letters to use the default loader, got %v", err)
+	}
+}
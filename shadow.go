@@ -0,0 +1,63 @@
+package templar
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template/parse"
+)
+
+// ShadowedGlobalError is returned under TemplateGroup.StrictGlobalRefs when
+// a namespaced file references a global template with the "::" escape (eg
+// {{ template "::formatDate" . }}) but also defines a template with that name.
+// The reference resolves to the global template, which may not be what the
+// author intended.
+type ShadowedGlobalError struct {
+	// Namespace is the namespace the file was imported into.
+	Namespace string
+
+	// Path is the imported file.
+	Path string
+
+	// Name is the global template referenced, without the "::" prefix.
+	Name string
+}
+
+func (e *ShadowedGlobalError) Error() string {
+	return fmt.Sprintf("%s (namespace %s) references ::%s but also defines %s (as %s:%s); the reference resolves to the global %s",
+		e.Path, e.Namespace, e.Name, e.Name, e.Namespace, e.Name, e.Name)
+}
+
+// shadowedGlobalRefs returns the names (sorted, without the "::" prefix) of
+// the global templates referenced with "::" by the kept templates of a file
+// that the file also defines itself.  trees holds every template defined in
+// the file and kept the names of those imported.
+func shadowedGlobalRefs(trees map[string]*parse.Tree, kept map[string]string) []string {
+	shadowed := make(map[string]bool)
+	for name := range kept {
+		tree := trees[name]
+		if tree == nil {
+			continue
+		}
+		WalkParseTree(tree.Root, func(node *parse.TemplateNode) {
+			if global, ok := strings.CutPrefix(node.Name, "::"); ok && trees[global] != nil {
+				shadowed[global] = true
+			}
+		})
+	}
+	return sortedKeys(shadowed)
+}
+
+// checkShadowedGlobals reports the shadowed global references of a
+// namespaced import: as a *ShadowedGlobalError under StrictGlobalRefs, and
+// as a warning otherwise.
+func (t *TemplateGroup) checkShadowedGlobals(curr *Template, shadowed []string) error {
+	for _, name := range shadowed {
+		err := &ShadowedGlobalError{Namespace: curr.Namespace, Path: curr.sourceName(), Name: name}
+		if t.StrictGlobalRefs {
+			return err
+		}
+		slog.Warn("global template reference shadowed by namespace", "error", err)
+	}
+	return nil
+}
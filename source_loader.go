@@ -2,6 +2,7 @@ package templar
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,6 +18,11 @@ type SourceConfig struct {
 	Ref     string   `yaml:"ref,omitempty"`     // Git ref - branch or commit (fallback if no version)
 	Include []string `yaml:"include,omitempty"` // Glob patterns to include (e.g., ["**/*.html"])
 	Exclude []string `yaml:"exclude,omitempty"` // Glob patterns to exclude (e.g., ["*_test.*"])
+
+	// PostFetch is a shell command run in the vendored directory after the
+	// source is fetched (e.g., "npm run build-css").  templar get only runs
+	// it with --allow-hooks, as it executes arbitrary commands.
+	PostFetch string `yaml:"post_fetch,omitempty"`
 }
 
 // GetRef returns the effective git ref (version takes precedence over ref)
@@ -37,11 +43,23 @@ type VendorConfig struct {
 	SearchPaths []string                `yaml:"search_paths"`
 	RequireLock bool                    `yaml:"require_lock"`
 
+	// Aliases maps logical names to template paths (eg header →
+	// shared/layouts/header.html or @goapplib/layouts/header.html).
+	// Templates reference them as "@header", so moving a file only needs
+	// its alias updated.  Alias targets are resolved like any other path
+	// (but never relative to the referencing template) and cannot
+	// themselves be aliases.
+	Aliases map[string]string `yaml:"aliases"`
+
 	// FS is the filesystem for template resolution. Required.
 	// SearchPaths and VendorDir are paths within this FS.
 	// Use NewLocalFS(root) for local disk, NewMemFS() for tests.
 	FS WritableFS `yaml:"-"`
 
+	// Fetcher downloads sources for FetchSource and FetchAllSources.
+	// Defaults to DefaultFetcher.
+	Fetcher Fetcher `yaml:"-"`
+
 	// configDir is the directory containing the config file (for resolving relative paths)
 	configDir string
 }
@@ -65,6 +83,11 @@ func LoadVendorConfigWithDefaults(path string, info ToolInfo) (*VendorConfig, er
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	for name, source := range config.Sources {
+		if ref := source.GetRef(); strings.HasPrefix(ref, "-") {
+			return nil, fmt.Errorf("source '%s': ref '%s' must not start with '-'", name, ref)
+		}
+	}
 
 	// Store the config directory for resolving relative paths. Absolutize
 	// so ResolveVendorDir/ResolveSearchPaths always return absolute paths,
@@ -207,9 +230,32 @@ func NewSourceLoader(config *VendorConfig) *SourceLoader {
 }
 
 // Load attempts to load templates matching the given pattern.
+// If the pattern is an @alias from the config, its target is loaded instead.
 // If the pattern starts with @sourcename/, it resolves to the vendored location.
 // Otherwise, it delegates to the underlying FileSystemLoader.
 func (s *SourceLoader) Load(pattern string, cwd string) ([]*Template, error) {
+	if target, ok := s.aliasTarget(pattern); ok {
+		templates, err := s.load(target, "")
+		if err != nil {
+			return nil, fmt.Errorf("alias '%s' (%s): %w", pattern, target, err)
+		}
+		return templates, nil
+	}
+	return s.load(pattern, cwd)
+}
+
+// aliasTarget returns the path an @alias pattern refers to.
+func (s *SourceLoader) aliasTarget(pattern string) (string, bool) {
+	name, ok := strings.CutPrefix(pattern, "@")
+	if !ok {
+		return "", false
+	}
+	target, ok := s.config.Aliases[name]
+	return target, ok
+}
+
+// load loads a pattern that is not an alias.
+func (s *SourceLoader) load(pattern string, cwd string) ([]*Template, error) {
 	// Check if pattern starts with @
 	if strings.HasPrefix(pattern, "@") {
 		return s.loadFromSource(pattern, cwd)
@@ -255,3 +301,79 @@ func (s *SourceLoader) loadFromSource(pattern string, cwd string) ([]*Template,
 
 	return vendorLoader.Load(vendoredBase, "")
 }
+
+// ReferencedSources returns the distinct names of the sources referenced
+// (via @sourcename/... in include and namespace directives) by root and,
+// transitively, by everything it includes.  This is useful for finding
+// sources in templar.yaml that are no longer used.
+//
+// References to sources not declared in the config are returned separately
+// in undeclared (and logged as warnings).  Directives are not executed, so
+// references in every branch of a conditional are counted.  Templates that
+// cannot be loaded (eg sources that have not been fetched yet) are skipped.
+func (s *SourceLoader) ReferencedSources(root *Template) (used []string, undeclared []string) {
+	usedSet := make(map[string]bool)
+	undeclaredSet := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	var visit func(tmpl *Template)
+	visit = func(tmpl *Template) {
+		if tmpl.Path != "" {
+			if visited[tmpl.Path] {
+				return
+			}
+			visited[tmpl.Path] = true
+		}
+		for _, d := range ParseDirectives(string(tmpl.RawSource)) {
+			var included string
+			switch {
+			case (d.Name == "include" || strings.HasPrefix(d.Name, "raw_include")) && len(d.Args) >= 1:
+				included = d.Args[0]
+			case (d.Name == "namespace" || d.Name == "include_ns") && len(d.Args) >= 2:
+				included = d.Args[1]
+			default:
+				continue
+			}
+
+			dir := tmpl.includeDir()
+			if target, ok := s.aliasTarget(included); ok {
+				included, dir = target, ""
+			}
+			if name, ok := sourceNameOf(included); ok {
+				if _, declared := s.config.Sources[name]; !declared {
+					if !undeclaredSet[name] {
+						slog.Warn("reference to undeclared source", "source", name, "template", tmpl.sourceName())
+					}
+					undeclaredSet[name] = true
+					continue
+				}
+				usedSet[name] = true
+			}
+			if strings.HasPrefix(d.Name, "raw_include") {
+				// Raw files are not templates so have no references of their own
+				continue
+			}
+
+			children, err := s.load(included, dir)
+			if err != nil {
+				slog.Warn("could not load referenced template", "included", included, "template", tmpl.sourceName(), "error", err)
+				continue
+			}
+			for _, child := range children {
+				visit(child)
+			}
+		}
+	}
+	visit(root)
+	return sortedKeys(usedSet), sortedKeys(undeclaredSet)
+}
+
+// sourceNameOf returns the source name of an @sourcename/path pattern.
+func sourceNameOf(pattern string) (string, bool) {
+	withoutAt, ok := strings.CutPrefix(pattern, "@")
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(withoutAt, "/")
+	return name, true
+}
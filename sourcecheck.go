@@ -0,0 +1,65 @@
+package templar
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// CheckSource compiles every template of the vendored source name (as a root
+// loaded through the loader as "@name/path") and returns an error for each
+// one that fails, eg due to a syntax error, a missing include or an extend
+// of an undefined template.  This catches broken template packs when they
+// are vendored rather than when a page first uses them.
+//
+// Each template is compiled in a fresh group so errors in one do not leak
+// into the others.  Template packs often call functions the application is
+// expected to provide, so functions that are not defined are stubbed rather
+// than reported.  The returned error is non-nil only if the source's
+// templates could not be listed (eg it has not been fetched).
+func (s *SourceLoader) CheckSource(name string) ([]error, error) {
+	if _, ok := s.config.Sources[name]; !ok {
+		return nil, fmt.Errorf("source '%s' not defined in config", name)
+	}
+	dir := s.config.VendorDir + "/" + name
+	lister := &FileSystemLoader{
+		Folders:    []FSFolder{{FS: s.config.FS, Path: dir}},
+		Extensions: s.extensions,
+	}
+	if info, err := fs.Stat(s.config.FS, dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("source '%s' has not been fetched (%s not found)", name, dir)
+	}
+	names, err := lister.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []error
+	for _, rel := range names {
+		pattern := "@" + name + "/" + rel
+		if err := s.checkTemplate(pattern); err != nil {
+			failures = append(failures, &TemplateError{Path: pattern, Err: err})
+		}
+	}
+	return failures, nil
+}
+
+// checkTemplate compiles the templates matching pattern in a fresh group,
+// stubbing any functions they call that are not defined.
+func (s *SourceLoader) checkTemplate(pattern string) error {
+	group := NewTemplateGroup()
+	group.Loader = s
+	templates, err := s.Load(pattern, "")
+	if err != nil {
+		return err
+	}
+	for _, tmpl := range templates {
+		stubs, err := stubMissingFuncs(group, tmpl)
+		if err != nil {
+			return err
+		}
+		if _, err := group.PreProcessHtmlTemplate(tmpl, stubs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
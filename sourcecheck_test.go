@@ -0,0 +1,48 @@
+package templar
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCheckSource tests that every template of a vendored source is compiled
+// and that broken ones are reported by path.
+func TestCheckSource(t *testing.T) {
+	mfs := NewMemFS()
+	mfs.SetFile("templar_modules/uikit/button.html", []byte(`{{ define "button" }}<button>{{ asLabel . }}</button>{{ end }}`))
+	mfs.SetFile("templar_modules/uikit/card.html", []byte("{{# include \"button.html\" #}}\n"+`{{ define "card" }}{{ template "button" . }}{{ end }}`))
+	mfs.SetFile("templar_modules/uikit/missing.html", []byte(`{{# include "nothere.html" #}}`))
+	mfs.SetFile("templar_modules/uikit/syntax.html", []byte(`{{ define "x" }}{{ if }}{{ end }}`))
+	mfs.SetFile("templar_modules/uikit/README.md", []byte(`{{ not a template`))
+
+	config := &VendorConfig{
+		FS:          mfs,
+		VendorDir:   "templar_modules",
+		SearchPaths: []string{"templates"},
+		Sources: map[string]SourceConfig{
+			"uikit":     {URL: "github.com/example/uikit"},
+			"unfetched": {URL: "github.com/example/unfetched"},
+		},
+	}
+	loader := NewSourceLoader(config)
+
+	failures, err := loader.CheckSource("uikit")
+	if err != nil {
+		t.Fatalf("CheckSource failed: %v", err)
+	}
+	if len(failures) != 2 {
+		t.Fatalf("expected 2 failures, got %v", failures)
+	}
+	for i, path := range []string{"@uikit/missing.html", "@uikit/syntax.html"} {
+		if !strings.HasPrefix(failures[i].Error(), path+": ") {
+			t.Errorf("expected failure %d for %s, got %v", i, path, failures[i])
+		}
+	}
+
+	if _, err := loader.CheckSource("unfetched"); err == nil || !strings.Contains(err.Error(), "has not been fetched") {
+		t.Errorf("expected not fetched error, got %v", err)
+	}
+	if _, err := loader.CheckSource("unknown"); err == nil {
+		t.Error("expected error for undeclared source")
+	}
+}
@@ -0,0 +1,92 @@
+package templar
+
+import (
+	"errors"
+	"fmt"
+	htmpl "html/template"
+	"io"
+	"io/fs"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceManifestFile is the name of the file a template pack can ship at its
+// root to describe itself.
+const SourceManifestFile = "templar.yaml"
+
+// SourceManifest is the metadata a template pack declares about itself in a
+// templar.yaml at its root.  Other keys in the file (eg the pack's own
+// sources) are ignored.
+type SourceManifest struct {
+	// Index is the path, relative to the pack's root, of a template that
+	// showcases the pack's components, eg "index.html".  It is rendered by
+	// PreviewSource and `templar sources --preview`.
+	Index string `yaml:"index,omitempty"`
+}
+
+// SourceManifest returns the manifest of the vendored source name.  A source
+// without a templar.yaml has an empty manifest.
+func (s *SourceLoader) SourceManifest(name string) (*SourceManifest, error) {
+	if _, ok := s.config.Sources[name]; !ok {
+		return nil, fmt.Errorf("source '%s' not defined in config", name)
+	}
+	dir := s.config.VendorDir + "/" + name
+	if info, err := fs.Stat(s.config.FS, dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("source '%s' has not been fetched (%s not found)", name, dir)
+	}
+
+	manifest := &SourceManifest{}
+	data, err := fs.ReadFile(s.config.FS, dir+"/"+SourceManifestFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return manifest, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s of source '%s': %w", SourceManifestFile, name, err)
+	}
+	return manifest, nil
+}
+
+// PreviewSource renders the index template declared in the manifest of the
+// vendored source name (loaded as "@name/index") with data, so a pack's
+// components can be seen before they are used.  As with CheckSource,
+// functions the index calls that are not defined in funcs are stubbed.
+func (s *SourceLoader) PreviewSource(w io.Writer, name string, data any, funcs map[string]any) error {
+	manifest, err := s.SourceManifest(name)
+	if err != nil {
+		return err
+	}
+	if manifest.Index == "" {
+		return fmt.Errorf("source '%s' does not declare an index template in its %s", name, SourceManifestFile)
+	}
+
+	group := NewTemplateGroup()
+	group.Loader = s
+	if funcs != nil {
+		group.AddFuncs(funcs)
+	}
+	root, err := s.Load("@"+name+"/"+manifest.Index, "")
+	if err != nil {
+		return err
+	}
+	stubs, err := stubMissingFuncs(group, root[0])
+	if err != nil {
+		return err
+	}
+	return group.RenderHtmlTemplate(w, root[0], "", data, stubs)
+}
+
+// stubMissingFuncs returns a func map with a stub, returning nil, for every
+// function that root calls and the group does not define.
+func stubMissingFuncs(group *TemplateGroup, root *Template) (htmpl.FuncMap, error) {
+	missing, err := group.RequiredFuncs(root)
+	if err != nil {
+		return nil, err
+	}
+	stubs := make(htmpl.FuncMap)
+	for _, fn := range missing {
+		stubs[fn] = func(...any) any { return nil }
+	}
+	return stubs, nil
+}
@@ -0,0 +1,52 @@
+package templar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestPreviewSource tests that a vendored source's index template, declared
+// in its own templar.yaml, is rendered with undefined functions stubbed.
+func TestPreviewSource(t *testing.T) {
+	mfs := NewMemFS()
+	mfs.SetFile("templar_modules/uikit/templar.yaml", []byte("index: index.html\nsources: {}\n"))
+	mfs.SetFile("templar_modules/uikit/button.html", []byte(`{{ define "button" }}<button>{{ . }}{{ icon "x" }}</button>{{ end }}`))
+	mfs.SetFile("templar_modules/uikit/index.html", []byte("{{# include \"button.html\" #}}\n"+`<h1>{{ .Title }}</h1>{{ template "button" "OK" }}`))
+	mfs.SetFile("templar_modules/plain/button.html", []byte(`<button></button>`))
+
+	config := &VendorConfig{
+		FS:          mfs,
+		VendorDir:   "templar_modules",
+		SearchPaths: []string{"templates"},
+		Sources: map[string]SourceConfig{
+			"uikit":     {URL: "github.com/example/uikit"},
+			"plain":     {URL: "github.com/example/plain"},
+			"unfetched": {URL: "github.com/example/unfetched"},
+		},
+	}
+	loader := NewSourceLoader(config)
+
+	manifest, err := loader.SourceManifest("uikit")
+	if err != nil {
+		t.Fatalf("SourceManifest failed: %v", err)
+	}
+	if manifest.Index != "index.html" {
+		t.Errorf("expected index.html, got %q", manifest.Index)
+	}
+
+	var buf bytes.Buffer
+	if err := loader.PreviewSource(&buf, "uikit", map[string]any{"Title": "UI Kit"}, nil); err != nil {
+		t.Fatalf("PreviewSource failed: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "<h1>UI Kit</h1><button>OK</button>" {
+		t.Errorf("unexpected preview: %q", got)
+	}
+
+	if err := loader.PreviewSource(&buf, "plain", nil, nil); err == nil || !strings.Contains(err.Error(), "does not declare an index") {
+		t.Errorf("expected missing index error, got %v", err)
+	}
+	if err := loader.PreviewSource(&buf, "unfetched", nil, nil); err == nil || !strings.Contains(err.Error(), "has not been fetched") {
+		t.Errorf("expected not fetched error, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package templar
+
+import "embed"
+
+// StandardLoaderOption configures the loader built by NewStandardLoader.
+type StandardLoaderOption func(*standardLoaderConfig)
+
+// standardLoaderConfig collects the optional parts of a standard loader.
+type standardLoaderConfig struct {
+	folders       []FSFolder
+	embeds        []embed.FS
+	defaultLoader TemplateLoader
+}
+
+// WithFolders searches folders before the configured sources, eg for
+// app-specific overrides of vendored templates.
+func WithFolders(folders ...FSFolder) StandardLoaderOption {
+	return func(c *standardLoaderConfig) {
+		c.folders = append(c.folders, folders...)
+	}
+}
+
+// WithEmbeds searches templates embedded in the binary after the configured
+// sources, eg for the defaults shipped with a library.
+func WithEmbeds(fss ...embed.FS) StandardLoaderOption {
+	return func(c *standardLoaderConfig) {
+		c.embeds = append(c.embeds, fss...)
+	}
+}
+
+// WithDefaultLoader sets the loader used when no other loader finds a
+// template.
+func WithDefaultLoader(loader TemplateLoader) StandardLoaderOption {
+	return func(c *standardLoaderConfig) {
+		c.defaultLoader = loader
+	}
+}
+
+// NewStandardLoader builds a LoaderList that resolves templates in the
+// recommended order:
+//
+//  1. the folders given with WithFolders (local overrides),
+//  2. a SourceLoader for config, ie its SearchPaths and @source/... names in
+//     its VendorDir (skipped if config is nil),
+//  3. the embedded filesystems given with WithEmbeds,
+//  4. the loader given with WithDefaultLoader, if any.
+//
+// For example:
+//
+//	config, _ := templar.LoadVendorConfig("templar.yaml")
+//	group.Loader = templar.NewStandardLoader(config,
+//		templar.WithFolders(templar.LocalFolder("overrides")),
+//		templar.WithEmbeds(defaultTemplates))
+func NewStandardLoader(config *VendorConfig, opts ...StandardLoaderOption) *LoaderList {
+	var c standardLoaderConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	list := &LoaderList{DefaultLoader: c.defaultLoader}
+	if len(c.folders) > 0 {
+		list.AddLoader(NewFileSystemLoader(c.folders...))
+	}
+	if config != nil {
+		list.AddLoader(NewSourceLoader(config))
+	}
+	if len(c.embeds) > 0 {
+		list.AddLoader(NewEmbedFSLoader(c.embeds...))
+	}
+	return list
+}
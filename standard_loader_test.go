@@ -0,0 +1,60 @@
+package templar
+
+import "testing"
+
+// TestNewStandardLoader verifies the resolution order of a standard loader:
+// local folders, then the config's search paths and vendored sources, then
+// embeds, then the default loader.
+func TestNewStandardLoader(t *testing.T) {
+	mfs := NewMemFS()
+	mfs.SetFile("templates/page.html", []byte("page"))
+	mfs.SetFile("templates/card.html", []byte("card"))
+	mfs.SetFile("templar_modules/uikit/button.html", []byte("vendored button"))
+	overrides := NewMemFS()
+	overrides.SetFile("card.html", []byte("card override"))
+
+	loader := NewStandardLoader(&VendorConfig{
+		Sources:     map[string]SourceConfig{"uikit": {URL: "github.com/example/uikit"}},
+		VendorDir:   "templar_modules",
+		SearchPaths: []string{"templates"},
+		FS:          mfs,
+	},
+		WithFolders(FSFolder{FS: overrides, Path: "."}),
+		WithEmbeds(testEmbedFS),
+		WithDefaultLoader(NewMapLoader(map[string]string{"fallback.html": "fallback"})),
+	)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"page.html", "page"},
+		{"card.html", "card override"},
+		{"@uikit/button.html", "vendored button"},
+		{"testdata/embed/shared/footer.html", `{{ define "footer" }}<footer>{{ end }}`},
+		{"fallback.html", "fallback"},
+	}
+	for _, tt := range tests {
+		tmpls, err := loader.Load(tt.name, "")
+		if err != nil {
+			t.Errorf("Load(%q) failed: %v", tt.name, err)
+			continue
+		}
+		if got := string(tmpls[0].RawSource); got != tt.want {
+			t.Errorf("Load(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := loader.Load("missing.html", ""); err != TemplateNotFound {
+		t.Errorf("Expected TemplateNotFound, got %v", err)
+	}
+}
+
+// TestNewStandardLoader_NoConfig verifies that a standard loader can be
+// built without a vendor config.
+func TestNewStandardLoader_NoConfig(t *testing.T) {
+	loader := NewStandardLoader(nil, WithEmbeds(testEmbedFS))
+	if _, err := loader.Load("testdata/embed/pages/home.html", ""); err != nil {
+		t.Errorf("Load failed: %v", err)
+	}
+}
@@ -6,8 +6,12 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"maps"
 	"path/filepath"
+	"sort"
+	"strings"
 	ttmpl "text/template"
+	"text/template/parse"
 
 	gotl "github.com/panyam/goutils/template"
 )
@@ -30,8 +34,16 @@ type Template struct {
 	cleanedSource string
 
 	// Path is the file path for this template if it was loaded from a file.
+	// Loaders set it to the path of the file within the filesystem it was
+	// loaded from (eg a LocalFolder or embed.FS), so it can be passed back to
+	// the loader and relative includes resolve against its directory.  It is
+	// empty for templates built from strings (see BaseDir).
 	Path string
 
+	// BaseDir is used as the directory for resolving relative includes when
+	// Path is empty (e.g., for templates built from strings).
+	BaseDir string
+
 	// Status indicates whether the template has been loaded and parsed.
 	Status int
 
@@ -48,6 +60,11 @@ type Template struct {
 	// Metadata stores extracted information from the template (e.g., FrontMatter).
 	Metadata map[string]any
 
+	// directiveMeta records, for each Metadata key set by a meta or default
+	// directive in the last walk, the value it had before, so the next walk
+	// starts without the directives' values.
+	directiveMeta map[string]priorMeta
+
 	// Namespace is set when this template was included via namespace directive.
 	// When set, all template definitions and references will be prefixed with this namespace.
 	Namespace string
@@ -57,9 +74,57 @@ type Template struct {
 	// transitive dependencies are included (tree-shaking).
 	NamespaceEntryPoints []string
 
+	// NamespacePath is the directory (with a trailing "/") of a file imported
+	// with a glob namespace pattern, relative to the pattern's base directory,
+	// eg "forms/" for components/forms/button.html imported with
+	// "components/*/*.html".  Names local to the file are prefixed with it
+	// after the namespace, so button becomes "UI:forms/button".  It is empty
+	// for files directly in the base directory and for plain imports.
+	NamespacePath string
+
 	// Extensions records extend directives to be processed after all templates are parsed.
 	// Each extension creates a new template by copying a source and rewiring references.
+	//
+	// After a walk this holds the extend directives in this template's own source.
+	// After the template is preprocessed as a root (e.g., PreProcessHtmlTemplate),
+	// it holds every extend directive recorded across the template and all the
+	// templates it pulled in, in the order they are applied.
 	Extensions []Extension
+
+	// extraExtensions are the extensions added with TemplateGroup.Extend.
+	// Unlike Extensions they survive walks, and they are applied after the
+	// extend directives whenever the template is preprocessed as a root.
+	extraExtensions []Extension
+
+	// Overrides records override directives (original template → replacement).
+	// After all templates are parsed, every reference to an original template
+	// anywhere in the compiled set is rewired to its replacement.
+	Overrides map[string]string
+
+	// Usings records using directives: namespaces whose templates are given
+	// unprefixed aliases (eg "UI:button" as "button") after all templates
+	// are parsed.
+	Usings []string
+
+	// FuncBindings records bindfunc directives, which create funcs with
+	// leading arguments fixed (see FuncBinding).
+	FuncBindings []FuncBinding
+
+	// Requires lists the data keys (dotted paths such as "User.Name") this template
+	// declared via the requires directive.  They are verified before rendering.
+	Requires []string
+
+	// requirements collects Requires from this template and everything it pulled in
+	// during the last preprocessing.
+	requirements []requirement
+
+	// appliedExtensions records the extensions applied when this template was
+	// last preprocessed as a root.
+	appliedExtensions []AppliedExtension
+
+	// shakenImports records the tree-shaken imports made when this template
+	// was last preprocessed as a root.
+	shakenImports []ShakenImport
 }
 
 // Extension represents an extend directive that creates a new template by copying
@@ -82,6 +147,218 @@ type Extension struct {
 	Rewrites map[string]string
 }
 
+// NewExtension returns an Extension copying source as dest with the given
+// rewrites (block name -> replacement), eg to build layouts programmatically
+// instead of with extend directives.  It fails if source or dest is empty,
+// if a rewrite has an empty block or replacement, or if a block is rewritten
+// to dest itself (which would make dest call itself forever).  rewrites is
+// copied.
+func NewExtension(source, dest string, rewrites map[string]string) (Extension, error) {
+	if source == "" {
+		return Extension{}, fmt.Errorf("extend requires a non-empty source template name")
+	}
+	if dest == "" {
+		return Extension{}, fmt.Errorf("extend requires a non-empty destination template name")
+	}
+	for block, override := range rewrites {
+		if block == "" || override == "" {
+			return Extension{}, fmt.Errorf("extend %s as %s: invalid rewrite %q -> %q: block and replacement must be non-empty", source, dest, block, override)
+		}
+		if override == dest {
+			return Extension{}, fmt.Errorf("extend %s as %s: rewriting %q to %q would make %s call itself", source, dest, block, override, dest)
+		}
+	}
+	return Extension{SourceTemplate: source, DestTemplate: dest, Rewrites: maps.Clone(rewrites)}, nil
+}
+
+// FuncBinding represents a bindfunc directive that creates a func calling
+// another with its leading arguments fixed (partial application).
+//
+// Syntax: {{# bindfunc "usd" "currency" "USD" #}}
+//
+// This makes {{ usd .Price }} equivalent to {{ currency "USD" .Price }}.
+type FuncBinding struct {
+	// Name is the name of the new func (e.g., "usd")
+	Name string
+
+	// Func is the name of the func it calls (e.g., "currency")
+	Func string
+
+	// Args are the fixed leading arguments passed to Func.
+	Args []any
+}
+
+// String summarizes the template for debug logging without dumping its
+// source, eg Template{Name: "page", Path: "/t/page.html", Source: 120 bytes, Deps: 2}.
+// Namespace and Extensions are included only when set.
+func (t *Template) String() string {
+	if t == nil {
+		return "Template{<nil>}"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Template{Name: %q, Path: %q, Source: %d bytes, Deps: %d", t.Name, t.Path, len(t.RawSource), len(t.includes))
+	if t.Namespace != "" {
+		fmt.Fprintf(&sb, ", Namespace: %q", t.Namespace)
+	}
+	if len(t.Extensions) > 0 {
+		fmt.Fprintf(&sb, ", Extensions: %d", len(t.Extensions))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// bindFuncDirective records a bindfunc directive on the template.
+func (t *Template) bindFuncDirective(name string, fn string, args ...any) (string, error) {
+	if name == "" || fn == "" {
+		return "", fmt.Errorf("bindfunc requires: name func [args...]")
+	}
+	t.FuncBindings = append(t.FuncBindings, FuncBinding{Name: name, Func: fn, Args: args})
+	return fmt.Sprintf("{{/* Bound '%s' */}}", name), nil
+}
+
+// SetMeta records a metadata value on the template, creating Metadata if needed.
+func (t *Template) SetMeta(key string, value any) {
+	if t.Metadata == nil {
+		t.Metadata = make(map[string]any)
+	}
+	t.Metadata[key] = value
+}
+
+// priorMeta is a Metadata value (or its absence) before a directive set it.
+type priorMeta struct {
+	value  any
+	exists bool
+}
+
+// setDirectiveMeta is SetMeta for the meta and default directives, which
+// remembers the key's previous value for resetDirectiveMeta.
+func (t *Template) setDirectiveMeta(key string, value any) {
+	if _, ok := t.directiveMeta[key]; !ok {
+		if t.directiveMeta == nil {
+			t.directiveMeta = make(map[string]priorMeta)
+		}
+		prior, exists := t.Metadata[key]
+		t.directiveMeta[key] = priorMeta{value: prior, exists: exists}
+	}
+	t.SetMeta(key, value)
+}
+
+// resetDirectiveMeta restores the Metadata keys set by directives in the
+// last walk to their previous values, so a directive removed from the
+// source does not leave its value behind.  Metadata set otherwise (eg front
+// matter) is kept.
+func (t *Template) resetDirectiveMeta() {
+	for key, prior := range t.directiveMeta {
+		if prior.exists {
+			t.Metadata[key] = prior.value
+		} else {
+			delete(t.Metadata, key)
+		}
+	}
+	t.directiveMeta = nil
+}
+
+// ExtensionNames returns the names of the templates created by this template's
+// Extensions (their DestTemplate), in order.
+func (t *Template) ExtensionNames() []string {
+	names := make([]string, 0, len(t.Extensions))
+	for _, ext := range t.Extensions {
+		names = append(names, ext.DestTemplate)
+	}
+	return names
+}
+
+// AppliedExtension describes how an extend directive was applied when
+// compiling a template: which template it produced, whether its source was
+// found, and which of its block → override rewrites actually took effect.
+type AppliedExtension struct {
+	Extension
+
+	// SourceFound is false if SourceTemplate did not exist when extensions were applied.
+	SourceFound bool
+
+	// Applied holds the rewrites whose block is referenced by the source
+	// template and were therefore rewired.
+	Applied map[string]string
+
+	// Unused lists the blocks in Rewrites that the source template never
+	// references.  These are usually typos or overrides targeting a nested
+	// template (which extend does not reach).
+	Unused []string
+}
+
+// newAppliedExtension records which rewrites of ext apply to the source tree.
+func newAppliedExtension(ext Extension, source *parse.Tree) AppliedExtension {
+	referenced := make(map[string]bool)
+	for _, name := range CollectTemplateNames(source) {
+		referenced[name] = true
+	}
+	applied := AppliedExtension{Extension: ext, SourceFound: true, Applied: make(map[string]string)}
+	for block, override := range ext.Rewrites {
+		if referenced[block] {
+			applied.Applied[block] = override
+		} else {
+			applied.Unused = append(applied.Unused, block)
+		}
+	}
+	sort.Strings(applied.Unused)
+	return applied
+}
+
+// AppliedExtensions returns the extensions applied (in order) the last time
+// this template was preprocessed as a root template.  Use this to debug
+// overrides that do not take effect.
+func (t *Template) AppliedExtensions() []AppliedExtension {
+	return t.appliedExtensions
+}
+
+// ShakenImport describes which templates tree-shaking kept and dropped when a
+// file was imported with entry points, eg
+// {{# namespace "C" "components.html" "used1" "used2" #}}.
+type ShakenImport struct {
+	// Path is the imported file.
+	Path string
+
+	// Namespace is the namespace the file was imported into, or empty for a
+	// selective include.
+	Namespace string
+
+	// EntryPoints are the templates requested by the import.
+	EntryPoints []string
+
+	// Kept are the templates (by their name in the file) reachable from the
+	// entry points and therefore imported, sorted.
+	Kept []string
+
+	// Dropped are the templates defined in the file that were not imported,
+	// sorted.
+	Dropped []string
+}
+
+// newShakenImport records which of the templates in all were kept by a
+// tree-shaken import of curr.
+func newShakenImport[V any](curr *Template, all map[string]*parse.Tree, kept map[string]V) ShakenImport {
+	shaken := ShakenImport{Path: curr.Path, Namespace: curr.Namespace, EntryPoints: curr.NamespaceEntryPoints}
+	for name := range all {
+		if _, ok := kept[name]; ok {
+			shaken.Kept = append(shaken.Kept, name)
+		} else {
+			shaken.Dropped = append(shaken.Dropped, name)
+		}
+	}
+	sort.Strings(shaken.Kept)
+	sort.Strings(shaken.Dropped)
+	return shaken
+}
+
+// ShakenImports returns the imports with entry points (in order) made the
+// last time this template was preprocessed as a root, along with the
+// templates tree-shaking dropped from each.  Use this to audit that
+// tree-shaking removed what was expected.
+func (t *Template) ShakenImports() []ShakenImport {
+	return t.shakenImports
+}
+
 // Returns the cleaned source of this template wihtout all the includes removed (but before they are preprocessed)
 func (t *Template) CleanedSource() (string, error) {
 	if t.cleanedSource == "" {
@@ -109,13 +386,36 @@ func (t *Template) CleanedSource() (string, error) {
 	return t.cleanedSource, nil
 }
 
+// includeDir returns the directory relative includes are resolved against:
+// the directory of Path, or BaseDir for path-less templates.
+func (t *Template) includeDir() string {
+	if t.Path != "" {
+		return filepath.Dir(t.Path)
+	}
+	return t.BaseDir
+}
+
+// sourceName identifies the template in errors: its Path, or its Name for
+// path-less templates.
+func (t *Template) sourceName() string {
+	if t.Path != "" {
+		return t.Path
+	}
+	if t.Name != "" {
+		return t.Name
+	}
+	return "<inline>"
+}
+
 // AddDependency adds another template as a dependency of this template.
 // It returns false if the dependency would create a cycle, true otherwise.
+// The same file included under different namespaces (or both globally and
+// in a namespace) is a different dependency each time.
 func (t *Template) AddDependency(another *Template) bool {
 	if t.Path != "" {
 		for _, child := range t.includes {
 			// TODO - check full cycles
-			if child.Path == another.Path {
+			if child.Path == another.Path && child.Namespace == another.Namespace {
 				return false
 			}
 		}
@@ -137,19 +437,77 @@ type TemplateLoader interface {
 	Load(pattern string, cwd string) (template []*Template, err error)
 }
 
+// Lister is an optional interface implemented by loaders that can enumerate
+// the templates they are able to provide.  This is useful for building
+// navigation, sitemaps or dev index pages without a separate manifest.
+type Lister interface {
+	// List returns the names of all templates this loader can provide.
+	// Each returned name can be passed back to Load (with an empty cwd).
+	List() ([]string, error)
+}
+
+// ErrNotListable is returned by the List method of loader wrappers whose
+// wrapped loader does not implement Lister, so callers can tell "cannot
+// list" apart from "lists nothing".
+var ErrNotListable = errors.New("loader cannot list its templates")
+
+// WalkTemplate executes root's directives, then walks the templates it
+// includes (in the order of their include directives) and finally calls
+// handler for root.  So handler is called for every template after the
+// templates it includes, with root last, as with Walker.ProcessedTemplate.
+// Unlike Walker, includes are walked after all of root's directives have
+// run, and namespace directives are not supported.
 func (root *Template) WalkTemplate(loader TemplateLoader, handler func(template *Template) error) (err error) {
+	return root.walkTemplate(loader, false, nil, nil, handler)
+}
+
+// walkTemplate is WalkTemplate with the option to remove directive-only lines
+// (see trimDirectiveLines), the data to execute directives with and the
+// bundles available to the bundle directive.
+func (root *Template) walkTemplate(loader TemplateLoader, trimLines bool, data any, bundles map[string][]string, handler func(template *Template) error) (err error) {
 	// An Inorder walk of of a template.  Unlike WalkTemplate which applies a PostOrder traversal (first collects all
 	// includes, processes them and then the root template), here we will process an included template as soon as it is
 	// encountered.
-	cwd := root.Path
-	if cwd != "" {
-		cwd = filepath.Dir(cwd)
-	}
+	cwd := root.includeDir()
 
 	// log.Println("Coming from : ", root.Name)
 	// defer log.Println("Finished with: ", root.Name, root.Path)
 	var includes []string
+	root.includes = nil
+	root.Requires = nil
+	root.FuncBindings = nil
+	root.resetDirectiveMeta()
 	fm := ttmpl.FuncMap{
+		"requires": func(keys ...string) string {
+			root.Requires = append(root.Requires, keys...)
+			return fmt.Sprintf("{{/* Requires: %v */}}", keys)
+		},
+		"bindfunc": root.bindFuncDirective,
+		"default":  root.defaultDirective,
+		"meta": func(key string, value string) string {
+			root.setDirectiveMeta(key, value)
+			return fmt.Sprintf("{{/* Meta: '%s' */}}", key)
+		},
+		"bundle": func(name string) (string, error) {
+			files, ok := bundles[name]
+			if !ok {
+				return "", fmt.Errorf("bundle not found: %s", name)
+			}
+			includes = append(includes, files...)
+			return fmt.Sprintf("{{/* Including bundle: '%s' */}}", name), nil
+		},
+		"raw_include": func(file string) (string, error) {
+			return rawInclude(loader, file, cwd)
+		},
+		"raw_include_html": func(file string) (string, error) {
+			return typedRawInclude(loader, "html", file, cwd)
+		},
+		"raw_include_js": func(file string) (string, error) {
+			return typedRawInclude(loader, "js", file, cwd)
+		},
+		"raw_include_css": func(file string) (string, error) {
+			return typedRawInclude(loader, "css", file, cwd)
+		},
 		"include": func(glob string) string {
 			log.Println("Coming to: ", glob)
 			// TODO - avoid duplicates
@@ -159,15 +517,26 @@ func (root *Template) WalkTemplate(loader TemplateLoader, handler func(template
 	}
 
 	// First parse the macro template
-	templ, err := ttmpl.New("").Funcs(fm).Delims("{{#", "#}}").Parse(string(root.RawSource))
+	// Plain Go templates pass through unchanged, without parsing
+	if !UsesDirectives(root.RawSource) {
+		root.ParsedSource = string(root.RawSource)
+		return handler(root)
+	}
+
+	source := expandVerbatim(string(root.RawSource))
+	if trimLines {
+		source = trimDirectiveLines(source)
+	}
+	templ, err := ttmpl.New("").Funcs(fm).Delims("{{#", "#}}").Parse(source)
 	if err != nil {
 		slog.Error("error template: ", "path", root.Path, "error", err)
 		return panicOrError(err)
 	}
 
 	// New execute it so that all includes are evaluated
-	buff := bytes.NewBufferString("")
-	if err := templ.Execute(buff, nil); err != nil {
+	buff := getBuffer()
+	defer putBuffer(buff)
+	if err := templ.Execute(buff, data); err != nil {
 		slog.Error("error preprocessing template: ", "path", root.Path, "error", err)
 		root.Error = err
 		return panicOrError(err)
@@ -189,7 +558,7 @@ func (root *Template) WalkTemplate(loader TemplateLoader, handler func(template
 					continue
 				}
 			}
-			err = child.WalkTemplate(loader, handler)
+			err = child.walkTemplate(loader, trimLines, data, bundles, handler)
 			if err != nil {
 				slog.Error("error walking", "included", included, "error", err)
 				root.Error = err
@@ -0,0 +1,385 @@
+package templar
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestMetaDirective verifies that meta directives are recorded into Metadata
+// for both html and text preprocessing without affecting the output.
+func TestMetaDirective(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": `{{# meta "layout" "wide" #}}{{# meta "cache" "1h" #}}Body`,
+	})
+
+	for _, asHtml := range []bool{true, false} {
+		templates, _ := group.Loader.Load("page.html", "")
+		root := templates[0]
+		var err error
+		if asHtml {
+			_, err = group.PreProcessHtmlTemplate(root, nil)
+		} else {
+			_, err = group.PreProcessTextTemplate(root, nil)
+		}
+		if err != nil {
+			t.Fatalf("PreProcess failed: %v", err)
+		}
+		if root.Metadata["layout"] != "wide" || root.Metadata["cache"] != "1h" {
+			t.Errorf("asHtml=%v: unexpected metadata %v", asHtml, root.Metadata)
+		}
+		if !strings.HasSuffix(root.ParsedSource, "Body") || strings.Contains(root.ParsedSource, "wide") {
+			t.Errorf("asHtml=%v: meta should not affect output, got %q", asHtml, root.ParsedSource)
+		}
+	}
+}
+
+// TestMetaDirective_Rewalk verifies that metadata set by meta and default
+// directives is dropped when a template is walked again without them, while
+// metadata set by the application is kept.
+func TestMetaDirective_Rewalk(t *testing.T) {
+	group := NewTemplateGroup()
+	for _, asHtml := range []bool{true, false} {
+		preprocess := func(root *Template) {
+			var err error
+			if asHtml {
+				_, err = group.PreProcessHtmlTemplate(root, nil)
+			} else {
+				_, err = group.PreProcessTextTemplate(root, nil)
+			}
+			if err != nil {
+				t.Fatalf("asHtml=%v: PreProcess failed: %v", asHtml, err)
+			}
+		}
+
+		appDefaults := map[string]any{"Lang": "en"}
+		root := &Template{
+			Name:      "page",
+			RawSource: []byte(`{{# meta "layout" "wide" #}}{{# meta "title" "Draft" #}}{{# default "Title" "Untitled" #}}Body`),
+			Metadata:  map[string]any{"title": "Home", DefaultsMetaKey: appDefaults},
+		}
+		preprocess(root)
+		if root.Metadata["layout"] != "wide" || root.Metadata["title"] != "Draft" {
+			t.Errorf("asHtml=%v: unexpected metadata %v", asHtml, root.Metadata)
+		}
+
+		// The template is edited to remove its directives
+		root.RawSource = []byte(`Body`)
+		preprocess(root)
+		want := map[string]any{"title": "Home", DefaultsMetaKey: appDefaults}
+		if !reflect.DeepEqual(root.Metadata, want) {
+			t.Errorf("asHtml=%v: got metadata %v after rewalk, want %v", asHtml, root.Metadata, want)
+		}
+	}
+}
+
+// TestTrimDirectiveLines verifies that directive-only lines are removed from
+// the rendered output when TrimDirectiveLines is set, and kept otherwise.
+func TestTrimDirectiveLines(t *testing.T) {
+	files := map[string]string{
+		"page.html":   "<html>\n  {{# include \"header.html\" #}}\n{{# meta \"a\" \"b\" #}} {{# requires \"Title\" #}}\n{{ template \"header\" . }}\n</html>",
+		"header.html": "{{ define \"header\" }}<h1>{{ .Title }}</h1>{{ end }}",
+	}
+	for _, trim := range []bool{true, false} {
+		group := NewTemplateGroup()
+		group.Loader = NewMapLoader(files)
+		group.TrimDirectiveLines = trim
+
+		got := renderWith(t, group, "page.html", map[string]any{"Title": "Hi"})
+		want := "<html>\n<h1>Hi</h1>\n</html>"
+		if !trim {
+			want = "<html>\n  \n \n<h1>Hi</h1>\n</html>"
+		}
+		if got != want {
+			t.Errorf("trim=%v: got %q, want %q", trim, got, want)
+		}
+	}
+}
+
+// TestWalkTrace verifies that the walker emits a JSON event per step.
+func TestWalkTrace(t *testing.T) {
+	loader := NewMapLoader(map[string]string{
+		"page.html":   `{{# include "shared.html" #}}{{# namespace "UI" "ui.html" #}}{{# extend "UI:card" "MyCard" "UI:icon" "myIcon" #}}`,
+		"shared.html": `{{ define "footer" }}F{{ end }}`,
+		"ui.html":     `{{ define "card" }}{{ template "icon" }}{{ end }}{{ define "icon" }}i{{ end }}`,
+	})
+	templates, _ := loader.Load("page.html", "")
+
+	var trace bytes.Buffer
+	w := Walker{Loader: loader, WalkTrace: &trace}
+	if err := w.Walk(templates[0]); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	var events []string
+	dec := json.NewDecoder(&trace)
+	for dec.More() {
+		var ev WalkEvent
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatalf("Invalid trace event: %v", err)
+		}
+		events = append(events, ev.Event+":"+ev.Template+":"+ev.File+ev.Dest)
+	}
+	want := []string{
+		"enter:page.html:",
+		"include:page.html:shared.html",
+		"enter:shared.html:",
+		"processed:shared.html:",
+		"namespace:page.html:ui.html",
+		"enter:ui.html:",
+		"processed:ui.html:",
+		"extend:page.html:MyCard",
+		"processed:page.html:",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got events %v, want %v", events, want)
+	}
+}
+
+// TestWalkerBuffer verifies that a walker left without a Buffer creates one
+// that can be read after the walk, with the included content flattened in.
+func TestWalkerBuffer(t *testing.T) {
+	loader := NewMapLoader(map[string]string{
+		"page.html":   `{{# include "header.html" #}}Page`,
+		"header.html": `Header|`,
+	})
+	templates, _ := loader.Load("page.html", "")
+	w := Walker{Loader: loader}
+	if err := w.Walk(templates[0]); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if w.Buffer == nil || w.Buffer.String() != templates[0].ParsedSource || !strings.HasPrefix(w.Buffer.String(), "Header|") {
+		t.Errorf("Expected the buffer to hold the walked content, got %v", w.Buffer)
+	}
+}
+
+// TestWalkerMaxDepth verifies that the walker does not descend beyond
+// MaxDepth levels of includes.
+func TestWalkerMaxDepth(t *testing.T) {
+	loader := NewMapLoader(map[string]string{
+		"page.html":  `{{# include "one.html" #}}`,
+		"one.html":   `{{# namespace "Two" "two.html" #}}`,
+		"two.html":   `{{# include "three.html" #}}`,
+		"three.html": `three`,
+	})
+	for depth, want := range map[int][]string{1: {"page.html", "one.html"}, 2: {"page.html", "one.html", "two.html"}, 0: {"page.html", "one.html", "two.html", "three.html"}} {
+		templates, _ := loader.Load("page.html", "")
+		var entered []string
+		w := Walker{Loader: loader, MaxDepth: depth, EnteringTemplate: func(tmpl *Template) (bool, error) {
+			entered = append(entered, tmpl.Path)
+			return false, nil
+		}}
+		if err := w.Walk(templates[0]); err != nil {
+			t.Fatalf("Walk failed: %v", err)
+		}
+		if !reflect.DeepEqual(entered, want) {
+			t.Errorf("MaxDepth %d: entered %v, want %v", depth, entered, want)
+		}
+	}
+}
+
+// TestWalkerOrder verifies the documented order in which Walker and
+// WalkTemplate report templates: post-order, children in directive order,
+// once per include, with cycles and skipped templates left out.
+func TestWalkerOrder(t *testing.T) {
+	loader := NewMapLoader(map[string]string{
+		"page.html":  `{{# include "a.html" #}}{{# namespace "UI" "ui.html" #}}{{# include "b.html" #}}{{# include "page.html" #}}`,
+		"a.html":     `{{# include "c.html" #}}`,
+		"b.html":     `{{# include "c.html" #}}{{# include "skip.html" #}}`,
+		"c.html":     `c`,
+		"ui.html":    `{{# include "d.html" #}}`,
+		"d.html":     `d`,
+		"skip.html":  `skip`,
+		"plain.html": `{{# include "a.html" #}}{{# include "b.html" #}}`,
+	})
+	load := func(name string) *Template {
+		templates, err := loader.Load(name, "")
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		return templates[0]
+	}
+
+	var entered, processed []string
+	w := Walker{
+		Loader: loader,
+		EnteringTemplate: func(tmpl *Template) (bool, error) {
+			entered = append(entered, tmpl.Path)
+			return tmpl.Path == "skip.html", nil
+		},
+		ProcessedTemplate: func(tmpl *Template) error {
+			processed = append(processed, tmpl.Path)
+			return nil
+		},
+	}
+	if err := w.Walk(load("page.html")); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if want := []string{"page.html", "a.html", "c.html", "ui.html", "d.html", "b.html", "c.html", "skip.html"}; !reflect.DeepEqual(entered, want) {
+		t.Errorf("entered %v, want %v", entered, want)
+	}
+	if want := []string{"c.html", "a.html", "d.html", "ui.html", "c.html", "b.html", "page.html"}; !reflect.DeepEqual(processed, want) {
+		t.Errorf("processed %v, want %v", processed, want)
+	}
+
+	// The text path reports the same order
+	processed = nil
+	err := load("plain.html").WalkTemplate(loader, func(tmpl *Template) error {
+		processed = append(processed, tmpl.Path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkTemplate failed: %v", err)
+	}
+	if want := []string{"c.html", "a.html", "c.html", "skip.html", "b.html", "plain.html"}; !reflect.DeepEqual(processed, want) {
+		t.Errorf("WalkTemplate processed %v, want %v", processed, want)
+	}
+}
+
+// TestWalkerStop verifies that returning ErrStopWalk from a callback ends
+// the walk, from any depth, without an error.
+func TestWalkerStop(t *testing.T) {
+	t.Setenv("PANIC_ON_TEMPLAR_ERRORS", "true")
+	loader := NewMapLoader(map[string]string{
+		"page.html":   `{{# include "a.html" #}}{{# namespace "UI" "ui.html" #}}{{# include "b.html" #}}`,
+		"a.html":      `a`,
+		"ui.html":     `{{# include "needle.html" #}}`,
+		"needle.html": `needle`,
+		"b.html":      `b`,
+	})
+	templates, _ := loader.Load("page.html", "")
+
+	for _, stopOnEnter := range []bool{false, true} {
+		var visited []string
+		w := Walker{Loader: loader}
+		visit := func(tmpl *Template) error {
+			visited = append(visited, tmpl.Path)
+			if tmpl.Path == "needle.html" {
+				return ErrStopWalk
+			}
+			return nil
+		}
+		if stopOnEnter {
+			w.EnteringTemplate = func(tmpl *Template) (bool, error) { return false, visit(tmpl) }
+		} else {
+			w.ProcessedTemplate = visit
+		}
+		if err := w.Walk(templates[0]); err != nil {
+			t.Errorf("stopOnEnter=%v: expected no error, got %v", stopOnEnter, err)
+		}
+		want := []string{"a.html", "needle.html"}
+		if stopOnEnter {
+			want = []string{"page.html", "a.html", "ui.html", "needle.html"}
+		}
+		if !reflect.DeepEqual(visited, want) {
+			t.Errorf("stopOnEnter=%v: visited %v, want %v", stopOnEnter, visited, want)
+		}
+		if templates[0].Error != nil {
+			t.Errorf("stopOnEnter=%v: root recorded error %v", stopOnEnter, templates[0].Error)
+		}
+	}
+}
+
+// TestRawInclude verifies that raw_include inserts a file's contents verbatim
+// without parsing them as a template, in both html and text modes.
+func TestRawInclude(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": `<div>{{# raw_include "logo.svg" #}}{{ .Name }}</div>`,
+		"logo.svg":  `<svg>{{ not a template }}</svg>`,
+	})
+	want := `<div><svg>{{ not a template }}</svg>Hi</div>`
+
+	if got := renderWith(t, group, "page.html", map[string]any{"Name": "Hi"}); got != want {
+		t.Errorf("html: got %q, want %q", got, want)
+	}
+
+	templates, _ := group.Loader.Load("page.html", "")
+	var buf bytes.Buffer
+	if err := group.RenderTextTemplate(&buf, templates[0], "", map[string]any{"Name": "Hi"}, nil); err != nil {
+		t.Fatalf("RenderTextTemplate failed: %v", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+}
+
+// TestTypedRawInclude verifies that raw_include_css/js/html inline files as
+// trusted content for the html escaping context they land in.
+func TestTypedRawInclude(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": `<style>{{# raw_include_css "critical.css" #}}</style>` +
+			`<script>{{# raw_include_js "boot.js" #}}</script>` +
+			`<a>{{# raw_include_html "icon.svg" #}}</a>`,
+		"critical.css": `body > p { color: "red" }`,
+		"boot.js":      `var x = {"a": 1} && "{{"`,
+		"icon.svg":     `<svg><path d="M0 0"/></svg>`,
+	})
+	got := renderWith(t, group, "page.html", nil)
+	want := `<style>body > p { color: "red" }</style><script>var x = {"a": 1} && "{{"</script><a><svg><path d="M0 0"/></svg></a>`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestTemplateString verifies that String summarizes a template without
+// dumping its source.
+func TestTemplateString(t *testing.T) {
+	tmpl := &Template{
+		Name:      "page",
+		Path:      "pages/page.html",
+		RawSource: []byte("<p>hello</p>"),
+		includes:  []*Template{{}, {}},
+	}
+	want := `Template{Name: "page", Path: "pages/page.html", Source: 12 bytes, Deps: 2}`
+	if got := tmpl.String(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	tmpl.Namespace = "UI"
+	tmpl.Extensions = []Extension{{SourceTemplate: "UI:layout", DestTemplate: "page"}}
+	want = `Template{Name: "page", Path: "pages/page.html", Source: 12 bytes, Deps: 2, Namespace: "UI", Extensions: 1}`
+	if got := tmpl.String(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// TestPlainTemplates verifies that templates without directives are passed
+// through unchanged by both preprocessing paths.
+func TestPlainTemplates(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"plain.html": "{{ define \"x\" }}<p>{{ . }} #}}</p>{{ end }}\n{{ template \"x\" . }}",
+		"page.html":  `{{# include "plain.html" #}}!`,
+	})
+
+	for _, asHtml := range []bool{true, false} {
+		templates, _ := group.Loader.Load("plain.html", "")
+		plain := templates[0]
+		var buf bytes.Buffer
+		var err error
+		if asHtml {
+			err = group.RenderHtmlTemplate(&buf, plain, "", "hi", nil)
+		} else {
+			err = group.RenderTextTemplate(&buf, plain, "", "hi", nil)
+		}
+		if err != nil {
+			t.Fatalf("asHtml=%v: render failed: %v", asHtml, err)
+		}
+		if plain.ParsedSource != string(plain.RawSource) {
+			t.Errorf("asHtml=%v: expected the source unchanged, got %q", asHtml, plain.ParsedSource)
+		}
+		if want := "\n<p>hi #}}</p>"; buf.String() != want {
+			t.Errorf("asHtml=%v: got %q, want %q", asHtml, buf.String(), want)
+		}
+	}
+
+	// Plain files included by a page are flattened into it as before
+	if got, want := renderWith(t, group, "page.html", "hi"), "\n<p>hi #}}</p>!"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package templar
+
+import (
+	htmpl "html/template"
+	"maps"
+	ttmpl "text/template"
+)
+
+// clearCaches drops the compiled templates held by the group, along with the
+// cached rewrites of namespaced imports, eg when the funcs or bundles
+// available to templates change.
+func (t *TemplateGroup) clearCaches() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.htmlTemplates)
+	clear(t.textTemplates)
+	clear(t.namespaceCache)
+}
+
+// ResetCaches drops all state the group has built up from loading and
+// rendering templates: compiled templates, cached namespaced imports, loaded
+// templates, their dependencies and RenderCached output.  Registered funcs,
+// the loader and other settings are kept, so this forces the next render to
+// start from cold, eg to benchmark cold vs warm renders without creating a
+// new group.
+func (t *TemplateGroup) ResetCaches() {
+	t.clearCaches()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.templates)
+	clear(t.dependencies)
+	clear(t.renderCache)
+}
+
+// cachedHtml returns the cached compiled html template for name, if any.
+func (t *TemplateGroup) cachedHtml(name string) *htmpl.Template {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.htmlTemplates[name]
+}
+
+// cachedText returns the cached compiled text template for name, if any.
+func (t *TemplateGroup) cachedText(name string) *ttmpl.Template {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.textTemplates[name]
+}
+
+// cacheHtml caches a compiled html template under name.
+func (t *TemplateGroup) cacheHtml(name string, out *htmpl.Template) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.htmlTemplates[name] = out
+}
+
+// cacheText caches a compiled text template under name.
+func (t *TemplateGroup) cacheText(name string, out *ttmpl.Template) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.textTemplates[name] = out
+}
+
+// cloneCache copies the compiled template cache into out.
+func (t *TemplateGroup) cloneCache(out *TemplateGroup) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	maps.Copy(out.htmlTemplates, t.htmlTemplates)
+	maps.Copy(out.textTemplates, t.textTemplates)
+}
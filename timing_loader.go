@@ -0,0 +1,93 @@
+package templar
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// LoadTiming records a single Load call made through a TimingLoader.
+type LoadTiming struct {
+	// Pattern and Cwd are the arguments passed to Load.
+	Pattern string
+	Cwd     string
+
+	// Path is the resolved path of the first matched template (empty on failure).
+	Path string
+
+	// Duration is how long the wrapped loader took.
+	Duration time.Duration
+
+	// Err is the error returned by the wrapped loader, if any.
+	Err error
+}
+
+// TimingLoader wraps any TemplateLoader and records how long each load takes.
+// It is the I/O counterpart to MemStats and helps find slow templates,
+// e.g. those loaded over the network.
+//
+//	timed := &templar.TimingLoader{Loader: group.Loader}
+//	group.Loader = timed
+//	// ... render ...
+//	for pattern, d := range timed.Stats() { fmt.Println(pattern, d) }
+//
+// Only the total time per pattern is kept, so it can wrap the loader of a
+// long-running server; use OnLoad to see individual loads.
+type TimingLoader struct {
+	// Loader is the wrapped loader.
+	Loader TemplateLoader
+
+	// OnLoad, if set, is called after every load.
+	OnLoad func(timing LoadTiming)
+
+	mu     sync.Mutex
+	totals map[string]time.Duration
+}
+
+// Load delegates to the wrapped loader and records the time it took.
+func (t *TimingLoader) Load(pattern string, cwd string) ([]*Template, error) {
+	timing := LoadTiming{Pattern: pattern, Cwd: cwd}
+	start := time.Now()
+	templates, err := t.Loader.Load(pattern, cwd)
+	timing.Duration = time.Since(start)
+	timing.Err = err
+	if len(templates) > 0 {
+		timing.Path = templates[0].Path
+	}
+
+	t.mu.Lock()
+	if t.totals == nil {
+		t.totals = make(map[string]time.Duration)
+	}
+	t.totals[pattern] += timing.Duration
+	t.mu.Unlock()
+	if t.OnLoad != nil {
+		t.OnLoad(timing)
+	}
+	return templates, err
+}
+
+// List delegates to the wrapped loader.  It returns ErrNotListable if the
+// wrapped loader does not implement Lister.
+func (t *TimingLoader) List() ([]string, error) {
+	if lister, ok := t.Loader.(Lister); ok {
+		return lister.List()
+	}
+	return nil, ErrNotListable
+}
+
+// Stats returns the total load time per pattern across all recorded loads.
+func (t *TimingLoader) Stats() map[string]time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := make(map[string]time.Duration, len(t.totals))
+	maps.Copy(stats, t.totals)
+	return stats
+}
+
+// Reset clears all recorded timings.
+func (t *TimingLoader) Reset() {
+	t.mu.Lock()
+	t.totals = nil
+	t.mu.Unlock()
+}
@@ -0,0 +1,65 @@
+package templar
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestTimingLoader verifies that loads made while rendering are reported with
+// their resolved paths and errors, and totalled per pattern.
+func TestTimingLoader(t *testing.T) {
+	inner := NewMapLoader(map[string]string{
+		"page.html":   `{{# include "header.html" #}}Page`,
+		"header.html": `Header`,
+	})
+	var seen []LoadTiming
+	timed := &TimingLoader{Loader: inner, OnLoad: func(lt LoadTiming) { seen = append(seen, lt) }}
+	group := NewTemplateGroup()
+	group.Loader = timed
+
+	renderWith(t, group, "page.html", nil)
+	renderWith(t, group, "page.html", nil)
+	timed.Load("missing.html", "")
+
+	if len(seen) != 5 {
+		t.Fatalf("Expected 5 reported loads, got %d", len(seen))
+	}
+	if seen[1].Pattern != "header.html" || seen[1].Path != "header.html" {
+		t.Errorf("Unexpected header timing: %+v", seen[1])
+	}
+	if seen[4].Err != TemplateNotFound || seen[4].Path != "" {
+		t.Errorf("Expected failed load to be reported, got %+v", seen[4])
+	}
+	stats := timed.Stats()
+	if len(stats) != 3 {
+		t.Errorf("Expected stats for 3 patterns, got %v", stats)
+	}
+	if want := seen[0].Duration + seen[2].Duration; stats["page.html"] != want {
+		t.Errorf("Expected page.html total %v, got %v", want, stats["page.html"])
+	}
+
+	timed.Reset()
+	if len(timed.Stats()) != 0 {
+		t.Error("Expected Reset to clear timings")
+	}
+}
+
+// TestTimingLoader_List verifies that List delegates to the wrapped loader
+// and reports ErrNotListable when it cannot list, which loader lists skip.
+func TestTimingLoader_List(t *testing.T) {
+	timed := &TimingLoader{Loader: NewMapLoader(map[string]string{"a.html": "A"})}
+	if names, err := timed.List(); err != nil || !reflect.DeepEqual(names, []string{"a.html"}) {
+		t.Errorf("Expected [a.html], got %v, %v", names, err)
+	}
+
+	unlistable := &TimingLoader{Loader: struct{ TemplateLoader }{NewMapLoader(map[string]string{"b.html": "B"})}}
+	if _, err := unlistable.List(); !errors.Is(err, ErrNotListable) {
+		t.Errorf("Expected ErrNotListable, got %v", err)
+	}
+
+	list := (&LoaderList{}).AddLoader(unlistable).AddLoader(timed)
+	if names, err := list.List(); err != nil || !reflect.DeepEqual(names, []string{"a.html"}) {
+		t.Errorf("Expected the unlistable loader to be skipped, got %v, %v", names, err)
+	}
+}
@@ -0,0 +1,114 @@
+package templar
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"text/template/parse"
+)
+
+// traceFuncName is the func called at the start of each template when
+// tracing a render.
+const traceFuncName = "_templarTrace"
+
+// TraceRender renders entry of root as HTML like RenderHtmlTemplate (without
+// applying a layout) and returns the names of the templates that were
+// executed (the root's body, defines and blocks), each once in the order
+// they were first executed.  Use it for coverage, eg to find the partials a
+// test suite's data never exercises.  Templates called through tryTemplate
+// are not recorded.
+//
+// Tracing works on a copy of the compiled templates, so it does not affect
+// other renders, but it is slower than a normal render.
+func (t *TemplateGroup) TraceRender(w io.Writer, root *Template, entry string, data any, funcs map[string]any) (executed []string, err error) {
+	out, err := t.PreProcessHtmlTemplate(root, funcs)
+	if err != nil {
+		return nil, panicOrError(err)
+	}
+	traced, err := out.Clone()
+	if err != nil {
+		return nil, panicOrError(err)
+	}
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	traced.Funcs(map[string]any{traceFuncName: func(name string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if !seen[name] {
+			seen[name] = true
+			executed = append(executed, name)
+		}
+		return false
+	}})
+	for _, tmpl := range out.Templates() {
+		if tmpl.Tree == nil || parse.IsEmptyTree(tmpl.Tree.Root) {
+			continue
+		}
+		tree, err := withTraceCall(tmpl.Tree, tmpl.Name())
+		if err != nil {
+			return nil, err
+		}
+		if _, err := traced.AddParseTree(tmpl.Name(), tree); err != nil {
+			return nil, panicOrError(err)
+		}
+	}
+
+	err = t.executeHtml(w, root, traced, entry, data)
+	return executed, err
+}
+
+// withTraceCall returns a copy of tree that starts with a call to the trace
+// func for name.  The call is wrapped in an if that never renders, so it
+// adds no output in any html/template context.
+func withTraceCall(tree *parse.Tree, name string) (*parse.Tree, error) {
+	trees := make(map[string]*parse.Tree)
+	call := parse.New("trace")
+	call.Mode = parse.SkipFuncCheck
+	if _, err := call.Parse(fmt.Sprintf("{{if %s %s}}{{end}}", traceFuncName, strconv.Quote(name)), "", "", trees); err != nil {
+		return nil, err
+	}
+	copied := tree.Copy()
+	copied.Root.Nodes = slices.Insert(copied.Root.Nodes, 0, trees["trace"].Root.Nodes[0])
+	return copied, nil
+}
+
+// ReachableTemplates returns the names (sorted) of the templates that entry
+// of root (or its body if entry is empty) can execute: the templates it
+// calls, directly or through other templates, regardless of the data.  This
+// is the static counterpart of TraceRender, ie a superset of the templates
+// any render executes (apart from those called dynamically with
+// tryTemplate).
+func (t *TemplateGroup) ReachableTemplates(root *Template, entry string) ([]string, error) {
+	out, err := t.PreProcessHtmlTemplate(root, nil)
+	if err != nil {
+		return nil, err
+	}
+	if entry == "" {
+		entry = out.Name()
+	}
+	if out.Lookup(entry) == nil {
+		return nil, fmt.Errorf("template %q not found in %s", entry, root.sourceName())
+	}
+	// Compiled templates have their final (namespaced) names, so every
+	// reference is followed, not just local ones as in ComputeReachableTemplates.
+	reachable := map[string]bool{entry: true}
+	queue := []string{entry}
+	for len(queue) > 0 {
+		tmpl := out.Lookup(queue[0])
+		queue = queue[1:]
+		if tmpl == nil || tmpl.Tree == nil {
+			continue
+		}
+		WalkParseTree(tmpl.Tree.Root, func(node *parse.TemplateNode) {
+			if !reachable[node.Name] && out.Lookup(node.Name) != nil {
+				reachable[node.Name] = true
+				queue = append(queue, node.Name)
+			}
+		})
+	}
+	return slices.Sorted(maps.Keys(reachable)), nil
+}
@@ -0,0 +1,52 @@
+package templar
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// TestTraceRender verifies that the templates executed by a render are
+// recorded once each, in order, without changing the output.
+func TestTraceRender(t *testing.T) {
+	group := NewTemplateGroup()
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": `{{# include "parts.html" #}}<h1>{{ template "title" . }}</h1>` +
+			`{{ range .Items }}{{ template "item" . }}{{ end }}{{ if .Admin }}{{ template "admin" . }}{{ end }}` +
+			`<script>var n = {{ block "count" . }}{{ len .Items }}{{ end }};</script>`,
+		"parts.html": `{{ define "title" }}Hi{{ end }}{{ define "item" }}<a href="{{ . }}">{{ template "label" . }}</a>{{ end }}` +
+			`{{ define "label" }}{{ . }}{{ end }}{{ define "admin" }}Admin{{ end }}{{ define "unused" }}{{ end }}`,
+	})
+	templates, _ := group.Loader.Load("page.html", "")
+	root := templates[0]
+	data := map[string]any{"Items": []string{"/a", "/b"}}
+
+	var traced, plain bytes.Buffer
+	executed, err := group.TraceRender(&traced, root, "", data, nil)
+	if err != nil {
+		t.Fatalf("TraceRender failed: %v", err)
+	}
+	if want := []string{"page.html", "title", "item", "label", "count"}; !reflect.DeepEqual(executed, want) {
+		t.Errorf("executed = %v, want %v", executed, want)
+	}
+	if err := group.RenderHtmlTemplate(&plain, root, "", data, nil); err != nil {
+		t.Fatalf("RenderHtmlTemplate failed: %v", err)
+	}
+	if traced.String() != plain.String() {
+		t.Errorf("tracing changed the output: %q vs %q", traced.String(), plain.String())
+	}
+
+	reachable, err := group.ReachableTemplates(root, "")
+	if err != nil {
+		t.Fatalf("ReachableTemplates failed: %v", err)
+	}
+	if want := []string{"admin", "count", "item", "label", "page.html", "title"}; !reflect.DeepEqual(reachable, want) {
+		t.Errorf("reachable = %v, want %v", reachable, want)
+	}
+	if reachable, _ := group.ReachableTemplates(root, "item"); !reflect.DeepEqual(reachable, []string{"item", "label"}) {
+		t.Errorf("reachable from item = %v", reachable)
+	}
+	if _, err := group.ReachableTemplates(root, "nothere"); err == nil {
+		t.Error("expected an error for an unknown entry")
+	}
+}
@@ -0,0 +1,47 @@
+package templar
+
+// TransformingLoader wraps any TemplateLoader and rewrites the RawSource of
+// every loaded template before templar parses it, eg to expand custom
+// shortcodes or apply a CSS-in-template transform.
+//
+//	group.Loader = &templar.TransformingLoader{
+//		Loader: group.Loader,
+//		Transform: func(path string, src []byte) ([]byte, error) {
+//			return expandShortcodes(src), nil
+//		},
+//	}
+//
+// Transform is called for every file loaded through the loader, including
+// files inlined with raw_include, so it can use path to pick the files it
+// applies to.
+type TransformingLoader struct {
+	// Loader is the wrapped loader.
+	Loader TemplateLoader
+
+	// Transform returns the new source of the template at path.  An error
+	// fails the load.
+	Transform func(path string, src []byte) ([]byte, error)
+}
+
+// Load delegates to the wrapped loader and transforms the source of each
+// loaded template.
+func (t *TransformingLoader) Load(pattern string, cwd string) ([]*Template, error) {
+	templates, err := t.Loader.Load(pattern, cwd)
+	if err != nil || t.Transform == nil {
+		return templates, err
+	}
+	for _, tmpl := range templates {
+		if tmpl.RawSource, err = t.Transform(tmpl.Path, tmpl.RawSource); err != nil {
+			return nil, panicOrError(err)
+		}
+	}
+	return templates, nil
+}
+
+// List delegates to the wrapped loader if it implements Lister.
+func (t *TransformingLoader) List() ([]string, error) {
+	if lister, ok := t.Loader.(Lister); ok {
+		return lister.List()
+	}
+	return nil, nil
+}
@@ -0,0 +1,35 @@
+package templar
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestTransformingLoader verifies that the source of every loaded template,
+// including includes, is transformed before parsing, and that transform
+// errors fail the render.
+func TestTransformingLoader(t *testing.T) {
+	inner := NewMapLoader(map[string]string{
+		"page.html":   `{{# include "button.html" #}}[[hello]]{{ template "button" }}`,
+		"button.html": `{{ define "button" }}[[click]]{{ end }}`,
+		"bad.html":    `x`,
+	})
+	group := NewTemplateGroup()
+	group.Loader = &TransformingLoader{Loader: inner, Transform: func(path string, src []byte) ([]byte, error) {
+		if path == "bad.html" {
+			return nil, errors.New("bad shortcode")
+		}
+		// Expand [[word]] shortcodes into <b>word</b>
+		src = bytes.ReplaceAll(src, []byte("[["), []byte("<b>"))
+		return bytes.ReplaceAll(src, []byte("]]"), []byte("</b>")), nil
+	}}
+
+	if got, want := renderWith(t, group, "page.html", nil), "<b>hello</b><b>click</b>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := group.Loader.Load("bad.html", ""); err == nil || !strings.Contains(err.Error(), "bad shortcode") {
+		t.Errorf("Expected transform error, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package templar
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// DataTypeError is returned when template data is not of the type a typed
+// render or func expects.
+type DataTypeError struct {
+	// Want is the expected type.
+	Want reflect.Type
+
+	// Got is the type of the data that was passed (nil for a nil value).
+	Got reflect.Type
+}
+
+func (e *DataTypeError) Error() string {
+	got := "nil"
+	if e.Got != nil {
+		got = e.Got.String()
+	}
+	return fmt.Sprintf("template data is %s, want %s", got, e.Want)
+}
+
+// RenderTyped renders root as html like RenderHtmlTemplate, but with data of
+// a declared type so that the shape of a template's data is checked by the
+// compiler at the call site, eg:
+//
+//	type ProductPage struct {
+//		Product Product
+//		Related []Product
+//	}
+//
+//	err := templar.RenderTyped(group, w, root, "", ProductPage{...}, nil)
+//
+// Go does not allow type parameters on methods, so this is a function taking
+// the group.  Use TypedFunc to get the data back as a T inside funcs.
+func RenderTyped[T any](group *TemplateGroup, w io.Writer, root *Template, entry string, data T, funcs map[string]any) error {
+	return group.RenderHtmlTemplate(w, root, entry, data, funcs)
+}
+
+// DataAs converts template data to T, returning a *DataTypeError if it is
+// not a T.  A non-nil *T is dereferenced so that pages rendered with either
+// a value or a pointer are accepted.
+func DataAs[T any](data any) (T, error) {
+	var zero T
+	switch v := data.(type) {
+	case T:
+		return v, nil
+	case *T:
+		if v != nil {
+			return *v, nil
+		}
+	}
+	return zero, &DataTypeError{Want: reflect.TypeOf(&zero).Elem(), Got: reflect.TypeOf(data)}
+}
+
+// TypedFunc adapts fn to a template func taking the data (usually `.` or
+// `$`) as an untyped value, converting it with DataAs so fn can work with a
+// T, eg:
+//
+//	group.AddFuncs(map[string]any{
+//		"price": templar.TypedFunc(func(p ProductPage) string { return p.Product.FormattedPrice() }),
+//	})
+//
+// and `{{ price $ }}` in the template.  Calling it with data of another type
+// fails the render with a *DataTypeError.
+func TypedFunc[T, R any](fn func(T) R) func(any) (R, error) {
+	return func(data any) (R, error) {
+		typed, err := DataAs[T](data)
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		return fn(typed), nil
+	}
+}
@@ -0,0 +1,67 @@
+package templar
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestRenderTyped verifies rendering with typed data and reading it back as a
+// T in a TypedFunc, including the error for data of another type.
+func TestRenderTyped(t *testing.T) {
+	type Page struct {
+		Title string
+		Tags  []string
+	}
+	group := NewTemplateGroup()
+	group.AddFuncs(map[string]any{
+		"tagCount": TypedFunc(func(p Page) int { return len(p.Tags) }),
+	})
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": `{{ .Title }} ({{ tagCount $ }} tags)`,
+	})
+	templates, _ := group.Loader.Load("page.html", "")
+
+	var buf bytes.Buffer
+	if err := RenderTyped(group, &buf, templates[0], "", Page{Title: "Home", Tags: []string{"a", "b"}}, nil); err != nil {
+		t.Fatalf("RenderTyped: %v", err)
+	}
+	if got, want := buf.String(), "Home (2 tags)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := RenderTyped(group, &buf, templates[0], "", &Page{Title: "Ptr"}, nil); err != nil {
+		t.Fatalf("RenderTyped with pointer: %v", err)
+	}
+	if got, want := buf.String(), "Ptr (0 tags)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	err := group.RenderHtmlTemplate(&buf, templates[0], "", map[string]any{"Title": "Map"}, nil)
+	var typeErr *DataTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Expected DataTypeError, got %v", err)
+	}
+	if typeErr.Got.String() != "map[string]interface {}" || typeErr.Want.Name() != "Page" {
+		t.Errorf("Unexpected types in error: %v", typeErr)
+	}
+}
+
+// TestDataAs verifies converting untyped data to a T.
+func TestDataAs(t *testing.T) {
+	type User struct{ Name string }
+	if u, err := DataAs[User](User{Name: "Ann"}); err != nil || u.Name != "Ann" {
+		t.Errorf("value: got %v, %v", u, err)
+	}
+	if u, err := DataAs[User](&User{Name: "Bob"}); err != nil || u.Name != "Bob" {
+		t.Errorf("pointer: got %v, %v", u, err)
+	}
+	for _, data := range []any{nil, (*User)(nil), "Ann"} {
+		var typeErr *DataTypeError
+		if _, err := DataAs[User](data); !errors.As(err, &typeErr) {
+			t.Errorf("DataAs(%#v): expected DataTypeError, got %v", data, err)
+		}
+	}
+}
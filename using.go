@@ -0,0 +1,41 @@
+package templar
+
+import (
+	"fmt"
+	htmpl "html/template"
+	"slices"
+	"strings"
+	"text/template/parse"
+)
+
+// processUsings adds an unprefixed alias for every template of each namespace
+// in usings (see the using directive), eg "button" for "UI:button", so a page
+// that mostly uses one imported namespace can drop the prefix.  The aliases
+// are copies of the namespaced templates, so references inside them still go
+// to the namespaced names.  It is an error for a namespace to never have been
+// imported or for an alias to collide with an existing template.
+func (t *TemplateGroup) processUsings(usings []string, imported map[string]bool, out *htmpl.Template) error {
+	for _, namespace := range usings {
+		if !imported[namespace] {
+			return fmt.Errorf("using references namespace '%s' which was never imported", namespace)
+		}
+		prefix := namespace + ":"
+		var names []string
+		for _, tmpl := range out.Templates() {
+			if strings.HasPrefix(tmpl.Name(), prefix) && tmpl.Tree != nil && !parse.IsEmptyTree(tmpl.Tree.Root) {
+				names = append(names, tmpl.Name())
+			}
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			alias := strings.TrimPrefix(name, prefix)
+			if existing := out.Lookup(alias); existing != nil && existing.Tree != nil {
+				return fmt.Errorf("using namespace '%s': %s collides with existing template %s", namespace, name, alias)
+			}
+			if _, err := out.AddParseTree(alias, CopyTreeWithRewrites(out.Lookup(name).Tree, nil)); err != nil {
+				return panicOrError(err)
+			}
+		}
+	}
+	return nil
+}
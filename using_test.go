@@ -0,0 +1,82 @@
+package templar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestUsing tests that the using directive aliases a namespace's templates
+// without the prefix, and that the aliases can be extended and overridden.
+func TestUsing(t *testing.T) {
+	ui := `{{ define "button" }}<button>{{ template "icon" . }}{{ .Text }}</button>{{ end }}
+{{ define "icon" }}[icon]{{ end }}`
+
+	result := loadAndRender(t, map[string]string{
+		"ui.html": ui,
+		"page.html": `{{# namespace "UI" "ui.html" #}}
+{{# using "UI" #}}
+{{ define "page" }}{{ template "button" . }}|{{ template "UI:button" . }}|{{ template "icon" . }}{{ end }}`,
+	}, "page.html", "page", map[string]any{"Text": "OK"})
+	if want := "<button>[icon]OK</button>|<button>[icon]OK</button>|[icon]"; strings.TrimSpace(result) != want {
+		t.Errorf("expected %q, got %q", want, result)
+	}
+
+	// Aliases keep calling the namespaced templates, so overriding those
+	// applies to the aliases too
+	result = loadAndRender(t, map[string]string{
+		"ui.html": ui,
+		"page.html": `{{# namespace "UI" "ui.html" #}}
+{{# using "UI" #}}
+{{# extend "button" "bigButton" "UI:icon" "bigIcon" #}}
+{{# override "UI:icon" "myIcon" #}}
+{{ define "myIcon" }}[mine]{{ end }}
+{{ define "bigIcon" }}[big]{{ end }}
+{{ define "page" }}{{ template "button" . }}|{{ template "bigButton" . }}{{ end }}`,
+	}, "page.html", "page", map[string]any{"Text": "OK"})
+	if want := "<button>[mine]OK</button>|<button>[big]OK</button>"; strings.TrimSpace(result) != want {
+		t.Errorf("expected %q, got %q", want, result)
+	}
+}
+
+// TestUsing_Errors tests that using fails for namespaces that were never
+// imported and for aliases that collide with existing templates.
+func TestUsing_Errors(t *testing.T) {
+	cases := map[string]struct {
+		page string
+		want string
+	}{
+		"not imported": {
+			page: `{{# using "UI" #}}{{ define "page" }}{{ end }}`,
+			want: "namespace 'UI' which was never imported",
+		},
+		"collision": {
+			page: `{{# namespace "UI" "ui.html" #}}
+{{# using "UI" #}}
+{{ define "icon" }}[mine]{{ end }}
+{{ define "page" }}{{ end }}`,
+			want: "UI:icon collides with existing template icon",
+		},
+		"no namespace": {
+			page: `{{# using #}}{{ define "page" }}{{ end }}`,
+			want: "using requires at least one namespace",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mfs := NewMemFS()
+			mfs.SetFile("ui.html", []byte(`{{ define "button" }}<button>{{ template "icon" . }}</button>{{ end }}{{ define "icon" }}[icon]{{ end }}`))
+			mfs.SetFile("page.html", []byte(tc.page))
+			group := NewTemplateGroup()
+			group.Loader = &FileSystemLoader{Folders: []FSFolder{{FS: mfs, Path: "."}}, Extensions: []string{"html"}}
+			templates, err := group.Loader.Load("page.html", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = group.RenderHtmlTemplate(&bytes.Buffer{}, templates[0], "page", nil, nil)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
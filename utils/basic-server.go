@@ -2,10 +2,16 @@ package utils
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"html"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -17,7 +23,56 @@ type BasicServer struct {
 	TemplateDirs []string
 	FuncMaps     []map[string]any
 	Templates    *templar.TemplateGroup
-	mux          *http.ServeMux
+
+	// Dev enables development-only conveniences such as an auto-generated
+	// index of all templates at "/" and parse checking all templates at
+	// startup.  Keep this off in production so the template list is not exposed.
+	Dev bool
+
+	// Routes supplies computed data for specific request paths (eg "/pages/home.html").
+	// Paths without an entry are rendered with an empty map.
+	Routes map[string]func(*http.Request) (any, error)
+
+	// CertFile and KeyFile enable HTTPS when both are set.  Otherwise the
+	// server falls back to plain HTTP.
+	CertFile string
+	KeyFile  string
+
+	// StaticCacheMaxAge, when non-zero, adds a "Cache-Control: max-age=..." header
+	// to every static file response.
+	StaticCacheMaxAge time.Duration
+
+	// StaticCacheImmutable additionally marks static responses as "immutable".
+	// Only enable this when asset file names are content hashed.
+	StaticCacheImmutable bool
+
+	// TLSConfig optionally customizes the TLS settings used when serving HTTPS.
+	TLSConfig *tls.Config
+
+	// AccessLog enables a structured access log entry (method, path, status,
+	// duration, bytes) for every request.
+	AccessLog bool
+
+	// Verbose logs the path of every request and a summary (name and path,
+	// never the contents) of the template loaded for it.
+	Verbose bool
+
+	// Logger receives access log entries.  Defaults to slog.Default().
+	Logger *slog.Logger
+
+	// HealthPath and ReadyPath are the liveness and readiness endpoints.
+	// They default to "/healthz" and "/readyz" and can be changed to avoid
+	// colliding with real templates.  The readiness check also verifies that
+	// every template directory is readable.
+	HealthPath string
+	ReadyPath  string
+
+	// Assets builds cache-busted URLs for files in the static dirs and is
+	// exposed to templates as the "asset" function.  It is populated from
+	// StaticDirs on Init.
+	Assets *templar.AssetURLs
+
+	mux *http.ServeMux
 }
 
 func (b *BasicServer) Init() {
@@ -27,7 +82,7 @@ func (b *BasicServer) Init() {
 	}
 
 	log.Println("Registering template folders: ", b.TemplateDirs)
-	b.Templates.Loader = (&templar.LoaderList{}).AddLoader(templar.NewFileSystemLoader(templar.LocalFolders(b.TemplateDirs...)...))
+	b.Templates.Loader = templar.NewStandardLoader(nil, templar.WithFolders(templar.LocalFolders(b.TemplateDirs...)...))
 	for _, fm := range b.FuncMaps {
 		b.Templates.AddFuncs(fm)
 	}
@@ -46,20 +101,33 @@ func (b *BasicServer) createMux() {
 	staticDirs := b.StaticDirs
 
 	log.Println("Registering static folders: ", staticDirs)
+	b.Assets = &templar.AssetURLs{}
 	for _, statics := range staticDirs {
-		parts := strings.Split(statics, ":")
-		prefix := parts[0]
-		localfolder := parts[1]
-		if strings.HasPrefix(prefix, "/") {
-			prefix = prefix[1:]
+		prefix, localfolder, err := parseStaticSpec(statics)
+		if err != nil {
+			log.Printf("Skipping static dir: %v", err)
+			continue
 		}
+		b.Assets.Mounts = append(b.Assets.Mounts, templar.AssetMount{Prefix: prefix, Folder: templar.LocalFolder(localfolder)})
 		prefix = "/" + prefix + "/"
-		b.mux.Handle(prefix, http.StripPrefix(prefix, http.FileServer(http.Dir(localfolder))))
+		b.mux.Handle(prefix, b.cacheControl(http.StripPrefix(prefix, http.FileServer(http.Dir(localfolder)))))
+	}
+
+	if _, ok := b.Templates.Funcs["asset"]; !ok {
+		b.Templates.AddFuncs(b.Assets.Funcs())
 	}
 
+	b.registerHealthChecks()
+
 	b.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("Path: %s", html.EscapeString(r.URL.Path)) // #nosec G706 -- escaped
+		if b.Verbose {
+			log.Printf("Path: %s", html.EscapeString(r.URL.Path)) // #nosec G706 -- escaped
+		}
 		template := r.URL.Path[1:]
+		if b.Dev && template == "" {
+			b.serveIndex(w)
+			return
+		}
 		entry := ""
 		if e := r.URL.Query()["entry"]; len(e) > 0 {
 			entry = e[0]
@@ -69,17 +137,218 @@ func (b *BasicServer) createMux() {
 			log.Printf("Template Load Error: %v", err)
 			http.Error(w, "Error rendering: "+html.EscapeString(err.Error()), http.StatusInternalServerError)
 		} else {
-			log.Printf("Got Template: %s", html.EscapeString(tmpl[0].Path)) // #nosec G706 -- escaped
-			if renderErr := b.Templates.RenderHtmlTemplate(w, tmpl[0], entry, map[string]any{}, nil); renderErr != nil {
+			if b.Verbose {
+				log.Printf("Got Template: %s (%s)", html.EscapeString(tmpl[0].Name), html.EscapeString(tmpl[0].Path)) // #nosec G706 -- escaped
+			}
+			data, err := b.routeData(r)
+			if err != nil {
+				log.Printf("Route Data Error: %v", err)
+				http.Error(w, "Error computing data: "+html.EscapeString(err.Error()), http.StatusInternalServerError)
+				return
+			}
+			var renderErr error
+			if isTextTemplate(tmpl[0]) {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				renderErr = b.Templates.RenderTextTemplate(w, tmpl[0], entry, data, nil)
+			} else {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				renderErr = b.Templates.RenderHtmlTemplate(w, tmpl[0], entry, data, nil)
+			}
+			if renderErr != nil {
 				log.Printf("Render error: %v", renderErr)
 			}
 		}
 	})
 }
 
+// isTextTemplate reports whether a template should be rendered as plain text
+// (without HTML escaping), ie when templar.DetectHtml reports it is not html:
+// by its extension (eg .txt), or by its content for extensions like .tmpl
+// that are used for both.
+func isTextTemplate(tmpl *templar.Template) bool {
+	return !templar.DetectHtml(tmpl)
+}
+
+// registerHealthChecks adds the health and readiness endpoints so they are
+// served without going through template rendering.
+func (b *BasicServer) registerHealthChecks() {
+	if b.HealthPath == "" {
+		b.HealthPath = "/healthz"
+	}
+	if b.ReadyPath == "" {
+		b.ReadyPath = "/readyz"
+	}
+	b.mux.HandleFunc(b.HealthPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok"))
+	})
+	b.mux.HandleFunc(b.ReadyPath, func(w http.ResponseWriter, r *http.Request) {
+		for _, dir := range b.TemplateDirs {
+			if _, err := os.ReadDir(dir); err != nil {
+				http.Error(w, "template directory not readable: "+dir, http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok"))
+	})
+}
+
+// handler returns the server's mux, wrapped with access logging if enabled.
+func (b *BasicServer) handler() http.Handler {
+	if !b.AccessLog {
+		return b.mux
+	}
+	logger := b.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	next := b.mux
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		logger.Info("request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration", time.Since(start),
+			"bytes", rec.bytes)
+	})
+}
+
+// statusRecorder captures the status code and number of bytes written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// parseStaticSpec parses a static dir spec of the form "<http_prefix>:<local_folder>".
+// Only the first colon separates the two parts so the folder itself may contain
+// colons.  A spec that starts with a drive letter (eg "C:\www") is rejected since
+// it has no prefix.
+func parseStaticSpec(spec string) (prefix string, folder string, err error) {
+	prefix, folder, found := strings.Cut(spec, ":")
+	if !found || folder == "" || isDriveLetter(prefix, folder) {
+		return "", "", fmt.Errorf("invalid static spec %q, expected prefix:folder", spec)
+	}
+	prefix = strings.Trim(prefix, "/")
+	return prefix, folder, nil
+}
+
+// isDriveLetter reports whether a spec split into prefix and rest was really a
+// Windows path such as "C:\www" or "C:/www".
+func isDriveLetter(prefix, rest string) bool {
+	if len(prefix) != 1 || !(('a' <= prefix[0] && prefix[0] <= 'z') || ('A' <= prefix[0] && prefix[0] <= 'Z')) {
+		return false
+	}
+	return strings.HasPrefix(rest, "\\") || strings.HasPrefix(rest, "/")
+}
+
+// cacheControl wraps a static handler so its responses carry a Cache-Control
+// header according to StaticCacheMaxAge and StaticCacheImmutable.
+func (b *BasicServer) cacheControl(next http.Handler) http.Handler {
+	if b.StaticCacheMaxAge <= 0 {
+		return next
+	}
+	value := fmt.Sprintf("public, max-age=%d", int64(b.StaticCacheMaxAge/time.Second))
+	if b.StaticCacheImmutable {
+		value += ", immutable"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", value)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeData returns the data registered for the request's path in Routes,
+// or an empty map if no route is registered.
+func (b *BasicServer) routeData(r *http.Request) (any, error) {
+	if route, ok := b.Routes[r.URL.Path]; ok && route != nil {
+		return route(r)
+	}
+	return map[string]any{}, nil
+}
+
+// serveIndex renders a simple page linking to every template that the
+// configured loader can enumerate via the templar.Lister interface.
+func (b *BasicServer) serveIndex(w http.ResponseWriter) {
+	lister, ok := b.Templates.Loader.(templar.Lister)
+	if !ok {
+		http.Error(w, "Template loader does not support listing", http.StatusNotImplemented)
+		return
+	}
+	names, err := lister.List()
+	if errors.Is(err, templar.ErrNotListable) {
+		http.Error(w, "Template loader does not support listing", http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		log.Printf("Template List Error: %v", err)
+		http.Error(w, "Error listing templates: "+html.EscapeString(err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html><head><title>Templates</title></head><body>\n")
+	sb.WriteString("<h1>Templates</h1>\n<ul>\n")
+	for _, name := range names {
+		link := (&url.URL{Path: "/" + name}).String()
+		fmt.Fprintf(&sb, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(link), html.EscapeString(name))
+	}
+	sb.WriteString("</ul>\n</body></html>\n")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+// checkTemplates parses every listable template so syntax errors are reported
+// at startup rather than on first request.
+func (b *BasicServer) checkTemplates() error {
+	lister, ok := b.Templates.Loader.(templar.Lister)
+	if !ok {
+		return nil
+	}
+	names, err := lister.List()
+	if errors.Is(err, templar.ErrNotListable) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	errs := b.Templates.ParseCheck(names)
+	for _, err := range errs {
+		log.Printf("Template Parse Error: %v", err)
+	}
+	return errors.Join(errs...)
+}
+
+// Serve initializes the server and listens on addr until the server fails or is closed.
+// In Dev mode all templates are parse checked first and any errors are returned.
+// HTTPS is used if CertFile and KeyFile are both set.
+// Errors (eg the address already being in use) are returned to the caller rather than
+// terminating the process.
 func (b *BasicServer) Serve(ctx context.Context, addr string) error {
 	b.Init()
 
+	if b.Dev {
+		if err := b.checkTemplates(); err != nil {
+			return err
+		}
+	}
+
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -88,12 +357,19 @@ func (b *BasicServer) Serve(ctx context.Context, addr string) error {
 		Addr:              addr,
 		ReadHeaderTimeout: 10 * time.Second,
 		BaseContext:       func(_ net.Listener) context.Context { return ctx },
-		Handler:           b.mux,
+		Handler:           b.handler(),
+		TLSConfig:         b.TLSConfig,
 	}
-	log.Println("Starting server on: ", addr)
-	err := server.ListenAndServe()
-	if err != nil {
-		log.Fatal("error starting server: ", err)
+	var err error
+	if b.CertFile != "" && b.KeyFile != "" {
+		log.Println("Starting HTTPS server on: ", addr)
+		err = server.ListenAndServeTLS(b.CertFile, b.KeyFile)
+	} else {
+		log.Println("Starting server on: ", addr)
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
 	}
 	return err
 }
@@ -0,0 +1,277 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/panyam/templar"
+)
+
+// TestParseStaticSpec verifies that static dir specs are split on the first
+// colon only, so folders with Windows drive letters are preserved, and that
+// malformed specs are rejected rather than panicking.
+func TestParseStaticSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		prefix  string
+		folder  string
+		wantErr bool
+	}{
+		{spec: "static:./public", prefix: "static", folder: "./public"},
+		{spec: "/static:./public", prefix: "static", folder: "./public"},
+		{spec: `static:C:\public`, prefix: "static", folder: `C:\public`},
+		{spec: "assets:C:/www/assets", prefix: "assets", folder: "C:/www/assets"},
+		{spec: "static", wantErr: true},
+		{spec: "static:", wantErr: true},
+		{spec: `C:\public`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		prefix, folder, err := parseStaticSpec(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseStaticSpec(%q): expected error, got prefix=%q folder=%q", tt.spec, prefix, folder)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseStaticSpec(%q): unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if prefix != tt.prefix || folder != tt.folder {
+			t.Errorf("parseStaticSpec(%q) = (%q, %q), want (%q, %q)", tt.spec, prefix, folder, tt.prefix, tt.folder)
+		}
+	}
+}
+
+// TestIsTextTemplate verifies that .txt templates are rendered as plain text,
+// .html ones as HTML, and .tmpl ones (and those without an extension)
+// according to their content.
+func TestIsTextTemplate(t *testing.T) {
+	for _, tt := range []struct {
+		path, source string
+		want         bool
+	}{
+		{"robots.txt", "User-agent: *", true},
+		{"sitemap.TXT", "<url></url>", true},
+		{"emails/welcome.tmpl", "Hello {{ .Name }}", true},
+		{"pages/card.tmpl", "<div>{{ .Title }}</div>", false},
+		{"pages/index.html", "Hello", false},
+		{"noext", "<p>{{ . }}</p>", false},
+	} {
+		tmpl := &templar.Template{Path: tt.path, RawSource: []byte(tt.source)}
+		if got := isTextTemplate(tmpl); got != tt.want {
+			t.Errorf("isTextTemplate(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// newTestServer initializes a BasicServer over a temporary template
+// directory holding files, letting configure set options before Init.
+func newTestServer(t *testing.T, files map[string]string, configure func(b *BasicServer)) *BasicServer {
+	t.Helper()
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := &BasicServer{TemplateDirs: []string{dir}, StaticDirs: []string{"static:" + t.TempDir()}}
+	if configure != nil {
+		configure(b)
+	}
+	b.Init()
+	return b
+}
+
+// serve sends a GET for target to the server's handler and returns the response.
+func serve(b *BasicServer, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	b.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+// loadOnly hides the Lister implementation of the loader it wraps.
+type loadOnly struct {
+	templar.TemplateLoader
+}
+
+// TestBasicServer_Render verifies that templates are rendered as html or
+// plain text according to templar.DetectHtml and that load errors are
+// reported as a 500.
+func TestBasicServer_Render(t *testing.T) {
+	b := newTestServer(t, map[string]string{
+		"page.html":  `<p>{{ "<b>" }}</p>`,
+		"card.tmpl":  `<div>{{ "<b>" }}</div>`,
+		"note.tmpl":  `Hello {{ "<b>" }}`,
+		"robots.txt": `Disallow: {{ "<b>" }}`,
+	}, nil)
+
+	for _, tt := range []struct {
+		path, contentType, body string
+	}{
+		{"/page.html", "text/html; charset=utf-8", "<p>&lt;b&gt;</p>"},
+		{"/card.tmpl", "text/html; charset=utf-8", "<div>&lt;b&gt;</div>"},
+		{"/note.tmpl", "text/plain; charset=utf-8", "Hello <b>"},
+		{"/robots.txt", "text/plain; charset=utf-8", "Disallow: <b>"},
+	} {
+		rec := serve(b, tt.path)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", tt.path, rec.Code)
+		}
+		if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.path, got, tt.contentType)
+		}
+		if got := strings.TrimSpace(rec.Body.String()); got != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.path, got, tt.body)
+		}
+	}
+
+	if rec := serve(b, "/missing.html"); rec.Code != http.StatusInternalServerError {
+		t.Errorf("missing template: status = %d, want 500", rec.Code)
+	}
+}
+
+// TestBasicServer_Routes verifies that route data is passed to the template
+// and that route errors are reported as a 500 without rendering.
+func TestBasicServer_Routes(t *testing.T) {
+	b := newTestServer(t, map[string]string{
+		"hello.html": `<p>Hello {{ .Name }}</p>`,
+		"fail.html":  `<p>never rendered</p>`,
+	}, func(b *BasicServer) {
+		b.Routes = map[string]func(*http.Request) (any, error){
+			"/hello.html": func(r *http.Request) (any, error) {
+				return map[string]any{"Name": r.URL.Query().Get("name")}, nil
+			},
+			"/fail.html": func(r *http.Request) (any, error) {
+				return nil, errors.New("no data")
+			},
+		}
+	})
+
+	rec := serve(b, "/hello.html?name=World")
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "<p>Hello World</p>" {
+		t.Errorf("hello: got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = serve(b, "/fail.html")
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("fail: status = %d, want 500", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "Error computing data: no data") || strings.Contains(body, "never rendered") {
+		t.Errorf("fail: unexpected body %q", body)
+	}
+}
+
+// TestBasicServer_DevIndex verifies that the Dev index links every template,
+// is only served in Dev mode, and reports a 501 for loaders that cannot list.
+func TestBasicServer_DevIndex(t *testing.T) {
+	files := map[string]string{
+		"index.html":       `<p>home</p>`,
+		"pages/about.html": `<p>about</p>`,
+	}
+
+	b := newTestServer(t, files, func(b *BasicServer) { b.Dev = true })
+	rec := serve(b, "/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	for _, link := range []string{`<a href="/index.html">index.html</a>`, `<a href="/pages/about.html">pages/about.html</a>`} {
+		if !strings.Contains(rec.Body.String(), link) {
+			t.Errorf("index missing %s in %q", link, rec.Body.String())
+		}
+	}
+
+	b.Templates.Loader = loadOnly{b.Templates.Loader}
+	if rec := serve(b, "/"); rec.Code != http.StatusNotImplemented {
+		t.Errorf("without Lister: status = %d, want 501", rec.Code)
+	}
+	b.Templates.Loader = &templar.TimingLoader{Loader: b.Templates.Loader}
+	if rec := serve(b, "/"); rec.Code != http.StatusNotImplemented {
+		t.Errorf("wrapped loader without Lister: status = %d, want 501", rec.Code)
+	}
+
+	// Outside Dev mode "/" is not an index
+	b = newTestServer(t, files, nil)
+	if rec := serve(b, "/"); rec.Code == http.StatusOK && strings.Contains(rec.Body.String(), "<h1>Templates</h1>") {
+		t.Errorf("index served outside Dev mode: %q", rec.Body.String())
+	}
+}
+
+// TestBasicServer_HealthChecks verifies the liveness and readiness endpoints,
+// including a readiness failure for an unreadable template directory and
+// custom endpoint paths.
+func TestBasicServer_HealthChecks(t *testing.T) {
+	b := newTestServer(t, nil, nil)
+	for _, path := range []string{"/healthz", "/readyz"} {
+		rec := serve(b, path)
+		if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+			t.Errorf("%s: got %d %q, want 200 \"ok\"", path, rec.Code, rec.Body.String())
+		}
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	b = newTestServer(t, nil, func(b *BasicServer) {
+		b.TemplateDirs = append(b.TemplateDirs, missing)
+		b.HealthPath = "/live"
+		b.ReadyPath = "/ready"
+	})
+	if rec := serve(b, "/live"); rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Errorf("/live: got %d %q, want 200 \"ok\"", rec.Code, rec.Body.String())
+	}
+	rec := serve(b, "/ready")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("/ready: status = %d, want 503", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "template directory not readable: "+missing) {
+		t.Errorf("/ready: unexpected body %q", rec.Body.String())
+	}
+}
+
+// TestBasicServer_AccessLog verifies that an access log entry with the
+// method, path, status and size is written for every request when enabled.
+func TestBasicServer_AccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	b := newTestServer(t, map[string]string{"page.html": `<p>hi</p>`}, func(b *BasicServer) {
+		b.AccessLog = true
+		b.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+	})
+
+	serve(b, "/page.html")
+	serve(b, "/missing.html")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log entries, got %q", buf.String())
+	}
+	for i, want := range []string{
+		"msg=request method=GET path=/page.html status=200 ",
+		"msg=request method=GET path=/missing.html status=500 ",
+	} {
+		if !strings.Contains(lines[i], want) || !strings.Contains(lines[i], " duration=") {
+			t.Errorf("log entry %d = %q, want it to contain %q and a duration", i, lines[i], want)
+		}
+	}
+	if !strings.Contains(lines[0], "bytes=9") {
+		t.Errorf("log entry 0 = %q, want bytes=9", lines[0])
+	}
+
+	// Without AccessLog nothing is logged
+	buf.Reset()
+	b.AccessLog = false
+	serve(b, "/page.html")
+	if buf.Len() != 0 {
+		t.Errorf("expected no log entries, got %q", buf.String())
+	}
+}
@@ -2,8 +2,10 @@ package templar
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -736,6 +738,22 @@ func TestLoadVendorConfig_NotFound(t *testing.T) {
 	}
 }
 
+// TestLoadVendorConfig_OptionLikeRef tests that refs and versions starting
+// with "-" are rejected so they are never passed to git as options.
+func TestLoadVendorConfig_OptionLikeRef(t *testing.T) {
+	for _, field := range []string{"ref: -f", "version: --orphan=x"} {
+		path := filepath.Join(t.TempDir(), "templar.yaml")
+		content := "sources:\n  uikit:\n    url: gitlab.com/example/uikit\n    " + field + "\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadVendorConfig(path)
+		if err == nil || !strings.Contains(err.Error(), "source 'uikit': ref") || !strings.Contains(err.Error(), "must not start with '-'") {
+			t.Errorf("%s: expected option-like ref error, got %v", field, err)
+		}
+	}
+}
+
 // TestFindVendorConfig tests finding templar.yaml in current or parent directories
 func TestFindVendorConfig(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "templar-config-test-*")
@@ -1140,6 +1158,26 @@ func TestWriteAndLoadLockFile(t *testing.T) {
 	}
 }
 
+// TestSourceVersionFuncs tests that templates can emit a source's resolved commit
+func TestSourceVersionFuncs(t *testing.T) {
+	lock := &VendorLock{
+		Version: 1,
+		Sources: map[string]LockedSource{
+			"uikit": {URL: "github.com/example/uikit", ResolvedCommit: "abc123def456"},
+		},
+	}
+	group := NewTemplateGroup()
+	group.AddFuncs(SourceVersionFuncs(lock))
+	group.Loader = NewMapLoader(map[string]string{
+		"page.html": `<meta name="uikit-version" content="{{ sourceVersion "uikit" }}">[{{ sourceVersion "unknown" }}]`,
+	})
+
+	got := renderWith(t, group, "page.html", nil)
+	if want := `<meta name="uikit-version" content="abc123def456">[]`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
 // TestVendorLock_VerifyIntegrity tests that lock file can verify vendored files haven't changed
 func TestVendorLock_VerifyIntegrity(t *testing.T) {
 	t.Skip("VendorLock verification not yet implemented")
@@ -1440,3 +1478,376 @@ func TestWriteLockFileFor_CustomBranding(t *testing.T) {
 		t.Error("Lock file should not contain 'templar get'")
 	}
 }
+
+// TestSourceLoader_ReferencedSources verifies that sources referenced
+// transitively are reported, along with undeclared ones.
+func TestSourceLoader_ReferencedSources(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, content := range map[string]string{
+		"templates/page.html":                  `{{# include "layout.html" #}}{{# namespace "UI" "@uikit/card.html" #}}{{# include "@missing/x.html" #}}`,
+		"templates/layout.html":                `{{# if .Wide #}}{{# include "@icons/icon.html" #}}{{# end #}}`,
+		"templar_modules/uikit/card.html":      `{{# include "@icons/badge.html" #}}{{# include "@charts/bar.html" #}}`,
+		"templar_modules/icons/icon.html":      `{{ define "icon" }}i{{ end }}`,
+		"templar_modules/icons/badge.html":     `{{ define "badge" }}b{{ end }}`,
+		"templar_modules/unused/whatever.html": `unused`,
+	} {
+		full := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+
+	loader := NewSourceLoader(&VendorConfig{
+		Sources: map[string]SourceConfig{
+			"uikit":  {URL: "github.com/example/uikit"},
+			"icons":  {URL: "github.com/example/icons"},
+			"charts": {URL: "github.com/example/charts"},
+			"unused": {URL: "github.com/example/unused"},
+		},
+		VendorDir:   filepath.Join(tmpDir, "templar_modules"),
+		SearchPaths: []string{filepath.Join(tmpDir, "templates")},
+	})
+	templates, err := loader.Load("page.html", "")
+	if err != nil {
+		t.Fatalf("Failed to load page.html: %v", err)
+	}
+
+	used, undeclared := loader.ReferencedSources(templates[0])
+	if want := []string{"charts", "icons", "uikit"}; !reflect.DeepEqual(used, want) {
+		t.Errorf("used = %v, want %v", used, want)
+	}
+	if want := []string{"missing"}; !reflect.DeepEqual(undeclared, want) {
+		t.Errorf("undeclared = %v, want %v", undeclared, want)
+	}
+}
+
+// TestSourceLoader_Aliases tests that @alias references load the aliased
+// local or vendored template and count towards the sources they point into.
+func TestSourceLoader_Aliases(t *testing.T) {
+	mfs := NewMemFS()
+	mfs.SetFile("templates/pages/home.html", []byte(`{{# include "@header" #}}|{{# include "@card" #}}`))
+	mfs.SetFile("templates/shared/layouts/header.html", []byte(`Header`))
+	mfs.SetFile("templar_modules/uikit/components/card.html", []byte(`Card`))
+
+	loader := NewSourceLoader(&VendorConfig{
+		Sources:     map[string]SourceConfig{"uikit": {URL: "github.com/example/uikit"}},
+		VendorDir:   "templar_modules",
+		SearchPaths: []string{"templates"},
+		Aliases: map[string]string{
+			"header": "shared/layouts/header.html",
+			"card":   "@uikit/components/card.html",
+			"gone":   "shared/missing.html",
+		},
+		FS: mfs,
+	})
+
+	group := NewTemplateGroup()
+	group.Loader = loader
+	if got := renderWith(t, group, "pages/home.html", nil); got != "Header|Card" {
+		t.Errorf("got %q, want %q", got, "Header|Card")
+	}
+
+	root, err := loader.Load("pages/home.html", "")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	used, undeclared := loader.ReferencedSources(root[0])
+	if !reflect.DeepEqual(used, []string{"uikit"}) || len(undeclared) != 0 {
+		t.Errorf("used %v, undeclared %v: want [uikit], []", used, undeclared)
+	}
+
+	if _, err := loader.Load("@gone", ""); err == nil || !strings.Contains(err.Error(), "alias '@gone'") {
+		t.Errorf("expected alias error, got %v", err)
+	}
+}
+
+// TestFetchSource_CustomFetcher tests that FetchSource fetches through the
+// configured Fetcher instead of the network.
+func TestFetchSource_CustomFetcher(t *testing.T) {
+	tmpDir := t.TempDir()
+	var fetched SourceConfig
+	config := &VendorConfig{
+		Sources: map[string]SourceConfig{
+			"uikit": {URL: "hg.example.com/uikit", Version: "v1.2.0"},
+		},
+		VendorDir: tmpDir,
+		Fetcher: FetcherFunc(func(source SourceConfig, destDir string) (string, error) {
+			fetched = source
+			if err := os.MkdirAll(filepath.Join(destDir, "components"), 0750); err != nil {
+				return "", err
+			}
+			for _, name := range []string{"base.html", "components/card.html"} {
+				if err := os.WriteFile(filepath.Join(destDir, name), []byte("x"), 0600); err != nil {
+					return "", err
+				}
+			}
+			return "abc123", nil
+		}),
+	}
+
+	result, err := FetchSource(config, "uikit")
+	if err != nil {
+		t.Fatalf("FetchSource failed: %v", err)
+	}
+	if fetched.URL != "hg.example.com/uikit" {
+		t.Errorf("fetcher got source %+v", fetched)
+	}
+	if result.ResolvedCommit != "abc123" || result.FilesExtracted != 2 || result.Version != "v1.2.0" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "uikit", "components", "card.html")); err != nil {
+		t.Errorf("expected fetched file: %v", err)
+	}
+
+	config.Fetcher = FetcherFunc(func(SourceConfig, string) (string, error) {
+		return "", fmt.Errorf("artifact store unavailable")
+	})
+	if _, err := FetchSource(config, "uikit"); err == nil || !strings.Contains(err.Error(), "artifact store unavailable") {
+		t.Errorf("expected fetcher error, got %v", err)
+	}
+}
+
+// stubGit replaces the command runner for the duration of a test.  clone
+// writes files into the clone directory; other commands are answered by
+// respond, keyed on their arguments.
+func stubGit(t *testing.T, files map[string]string, respond func(args string) (string, error)) *[]string {
+	var calls []string
+	saved := runner
+	t.Cleanup(func() { runner = saved })
+	runner = func(name string, args ...string) ([]byte, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		if args[0] == "clone" {
+			dir := args[len(args)-1]
+			for file, content := range files {
+				path := filepath.Join(dir, file)
+				if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+					return nil, err
+				}
+				if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		}
+		out, err := respond(strings.Join(args[2:], " "))
+		return []byte(out), err
+	}
+	return &calls
+}
+
+// TestFetchFromGit tests git based fetching with a stubbed command runner.
+func TestFetchFromGit(t *testing.T) {
+	files := map[string]string{
+		".git/HEAD":                      "ref",
+		"README.md":                      "readme",
+		"templates/base.html":            "base",
+		"templates/components/card.html": "card",
+		"templates/card_test.html":       "test",
+	}
+	source := SourceConfig{URL: "gitlab.com/example/uikit", Path: "templates", Ref: "release", Exclude: []string{"*_test.*"}}
+
+	t.Run("FallsBackToRemoteBranch", func(t *testing.T) {
+		calls := stubGit(t, files, func(args string) (string, error) {
+			switch args {
+			case "checkout --quiet release":
+				return "error: pathspec 'release' did not match", fmt.Errorf("exit status 1")
+			case "rev-parse HEAD":
+				return "abc123\n", nil
+			}
+			return "", nil
+		})
+		destDir := t.TempDir()
+		commit, count, err := fetchFromGit(source, destDir, source.GetRef())
+		if err != nil {
+			t.Fatalf("fetchFromGit failed: %v", err)
+		}
+		if commit != "abc123" || count != 2 {
+			t.Errorf("got commit %q, %d files; want abc123, 2", commit, count)
+		}
+		if !strings.Contains((*calls)[0], "https://gitlab.com/example/uikit") {
+			t.Errorf("expected clone of https URL, got %q", (*calls)[0])
+		}
+		if !strings.HasSuffix((*calls)[2], "checkout --quiet origin/release") {
+			t.Errorf("expected origin/ fallback, got %v", *calls)
+		}
+		if _, err := os.Stat(filepath.Join(destDir, "components", "card.html")); err != nil {
+			t.Errorf("expected copied file: %v", err)
+		}
+	})
+
+	t.Run("UnknownRef", func(t *testing.T) {
+		stubGit(t, files, func(args string) (string, error) {
+			if strings.HasPrefix(args, "checkout") {
+				return "error: pathspec did not match", fmt.Errorf("exit status 1")
+			}
+			return "", nil
+		})
+		_, _, err := fetchFromGit(source, t.TempDir(), "nope")
+		want := "ref 'nope' not found: git -C"
+		if err == nil || !strings.Contains(err.Error(), want) || !strings.Contains(err.Error(), "pathspec did not match") {
+			t.Errorf("expected error containing %q and git's output, got %v", want, err)
+		}
+	})
+}
+
+// TestGitCheckout tests how tags, branches and commit hashes are checked out.
+func TestGitCheckout(t *testing.T) {
+	const fullSHA = "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		name    string
+		ref     string
+		known   []string // refs that checkout succeeds for
+		fetches bool     // whether fetch makes the ref checkoutable
+		calls   []string
+		wantErr string
+	}{
+		{"Tag", "v1.2.0", []string{"v1.2.0"}, false,
+			[]string{"checkout --quiet v1.2.0"}, ""},
+		{"RemoteBranch", "develop", []string{"origin/develop"}, false,
+			[]string{"checkout --quiet develop", "checkout --quiet origin/develop"}, ""},
+		{"UnknownBranch", "nope", nil, false,
+			[]string{"checkout --quiet nope", "checkout --quiet origin/nope"}, "ref 'nope' not found"},
+		{"ShortSHA", "0123abc", []string{"0123abc"}, false,
+			[]string{"checkout --quiet 0123abc"}, ""},
+		{"UnknownShortSHA", "0123abc", nil, false,
+			[]string{"checkout --quiet 0123abc"}, "commit '0123abc' not found (abbreviated"},
+		{"FullSHA", fullSHA, []string{fullSHA}, false,
+			[]string{"checkout --quiet " + fullSHA}, ""},
+		{"UnclonedFullSHA", fullSHA, nil, true,
+			[]string{"checkout --quiet " + fullSHA, "fetch --quiet origin " + fullSHA, "checkout --quiet " + fullSHA}, ""},
+		{"UnknownFullSHA", fullSHA, nil, false,
+			[]string{"checkout --quiet " + fullSHA, "fetch --quiet origin " + fullSHA}, "commit '" + fullSHA + "' not found"},
+		{"OptionLikeRef", "--orphan=x", []string{"--orphan=x"}, false,
+			nil, "ref '--orphan=x' must not start with '-'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			known := make(map[string]bool)
+			for _, ref := range tt.known {
+				known[ref] = true
+			}
+			var calls []string
+			stubGit(t, nil, func(args string) (string, error) {
+				calls = append(calls, args)
+				if ref, ok := strings.CutPrefix(args, "fetch --quiet origin "); ok {
+					if !tt.fetches {
+						return "fatal: couldn't find remote ref", fmt.Errorf("exit status 128")
+					}
+					known[ref] = true
+					return "", nil
+				}
+				if ref, ok := strings.CutPrefix(args, "checkout --quiet "); ok && known[ref] {
+					return "", nil
+				}
+				return "error: pathspec did not match", fmt.Errorf("exit status 1")
+			})
+
+			err := gitCheckout("/clone", tt.ref)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(calls, tt.calls) {
+				t.Errorf("git calls %q, want %q", calls, tt.calls)
+			}
+		})
+	}
+}
+
+// TestFetchLockedSource tests that frozen fetches use the locked commit and
+// fail on drift between the config and the lock.
+func TestFetchLockedSource(t *testing.T) {
+	var fetchedRef string
+	config := &VendorConfig{
+		Sources: map[string]SourceConfig{
+			"uikit": {URL: "gitlab.com/example/uikit", Version: "v1.2.0"},
+			"extra": {URL: "gitlab.com/example/extra"},
+		},
+		VendorDir: t.TempDir(),
+		Fetcher: FetcherFunc(func(source SourceConfig, destDir string) (string, error) {
+			fetchedRef = source.GetRef()
+			return fetchedRef, nil
+		}),
+	}
+	lock := &VendorLock{
+		Version: 1,
+		Sources: map[string]LockedSource{
+			"uikit": {URL: "gitlab.com/example/uikit", Version: "v1.2.0", ResolvedCommit: "abc123"},
+		},
+	}
+
+	result, err := FetchLockedSource(config, "uikit", lock)
+	if err != nil {
+		t.Fatalf("FetchLockedSource failed: %v", err)
+	}
+	if fetchedRef != "abc123" || result.ResolvedCommit != "abc123" || result.Version != "v1.2.0" {
+		t.Errorf("fetched ref %q, result %+v", fetchedRef, result)
+	}
+
+	if _, err := FetchLockedSource(config, "extra", lock); err == nil || !strings.Contains(err.Error(), "not in the lock file") {
+		t.Errorf("expected missing lock entry error, got %v", err)
+	}
+
+	config.Sources["uikit"] = SourceConfig{URL: "gitlab.com/example/uikit", Version: "v1.3.0"}
+	if _, err := FetchLockedSource(config, "uikit", lock); err == nil || !strings.Contains(err.Error(), "changed since it was locked") {
+		t.Errorf("expected drift error, got %v", err)
+	}
+
+	config.Sources["uikit"] = SourceConfig{URL: "gitlab.com/example/uikit", Version: "v1.2.0"}
+	config.Fetcher = FetcherFunc(func(SourceConfig, string) (string, error) { return "def456", nil })
+	if _, err := FetchLockedSource(config, "uikit", lock); err == nil || !strings.Contains(err.Error(), "resolved to def456, locked at abc123") {
+		t.Errorf("expected commit mismatch error, got %v", err)
+	}
+}
+
+// TestRunPostFetch tests that post_fetch commands run in the vendored
+// directory and that their output is surfaced on failure.
+func TestRunPostFetch(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "vendored dir")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := RunPostFetch(SourceConfig{PostFetch: "echo built > out.css"}, dir); err != nil {
+		t.Fatalf("RunPostFetch failed: %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "out.css")); err != nil || string(content) != "built\n" {
+		t.Errorf("expected out.css in vendored dir, got %q, %v", content, err)
+	}
+
+	_, err := RunPostFetch(SourceConfig{PostFetch: "echo sass: not found; exit 3"}, dir)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "sass: not found") {
+		t.Errorf("expected failure with output, got %v", err)
+	}
+
+	if out, err := RunPostFetch(SourceConfig{}, dir); out != "" || err != nil {
+		t.Errorf("expected no-op without post_fetch, got %q, %v", out, err)
+	}
+}
+
+// TestLoadLockFile_Version tests that lock files newer than supported are
+// rejected and unversioned ones are upgraded.
+func TestLoadLockFile_Version(t *testing.T) {
+	mfs := NewMemFS()
+	mfs.SetFile("new.lock", []byte("version: 2\nsources: {}\n"))
+	mfs.SetFile("old.lock", []byte("sources:\n  uikit:\n    url: github.com/example/uikit\n    resolved_commit: abc123\n"))
+	mfs.SetFile("empty.lock", []byte("version: 1\n"))
+
+	if _, err := LoadLockFileFS(mfs, "new.lock"); err == nil || !strings.Contains(err.Error(), "lock file version 2 requires a newer templar") {
+		t.Errorf("expected version error, got %v", err)
+	}
+
+	lock, err := LoadLockFileFS(mfs, "old.lock")
+	if err != nil {
+		t.Fatalf("LoadLockFileFS failed: %v", err)
+	}
+	if lock.Version != LockFileVersion || lock.Sources["uikit"].ResolvedCommit != "abc123" {
+		t.Errorf("unexpected upgraded lock %+v", lock)
+	}
+
+	lock, err = LoadLockFileFS(mfs, "empty.lock")
+	if err != nil || lock.Sources == nil {
+		t.Errorf("expected empty Sources map, got %+v, %v", lock, err)
+	}
+}
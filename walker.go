@@ -2,18 +2,53 @@ package templar
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
-	"path/filepath"
+	"regexp"
+	"strings"
 	ttmpl "text/template"
 )
 
+// ErrStopWalk can be returned by a Walker's EnteringTemplate or
+// ProcessedTemplate callbacks to end the walk early without failing, eg once
+// a search has found what it was looking for.  Walk then returns nil.  Like
+// filepath.SkipAll, it is never returned by Walk itself.
+var ErrStopWalk = errors.New("stop walk")
+
 // Walker provides a mechanism for walking through templates and their dependencies
 // in a customizable way, applying visitor patterns as templates are processed.
-// Unlike the WalkTemplate method which uses post-order traversal, Walker implements
-// in-order traversal, processing includes immediately when encountered.
+//
+// A template's directives are executed in source order, and an included (or
+// namespaced) file is walked as soon as its directive is executed, so its
+// content is written into the buffer at the directive's position.  The
+// callbacks fire in a fixed order that PreProcessHtmlTemplate (and tooling
+// built on Walker) relies on:
+//
+//   - EnteringTemplate fires for a template before its directives run (a
+//     pre-order).
+//   - ProcessedTemplate fires once a template's directives have run, and so
+//     after ProcessedTemplate has fired for everything it includes: a
+//     post-order in which children are visited in the order of their
+//     directives, and the root is always last.
+//   - Files matched by one directive (eg a glob) are visited in the order the
+//     Loader returns them.
+//   - A file included from several templates is walked, and reported, once
+//     per include.  An include that would form a cycle, or is skipped by
+//     FoundInclude, EnteringTemplate or MaxDepth, is not reported.
+//
+// For example, if page.html includes a.html (which includes c.html), then
+// namespaces ui.html and then includes b.html (which also includes c.html),
+// ProcessedTemplate fires for c, a, ui, c, b and finally page.
+//
+// TemplateGroup's text path (WalkTemplate) reports templates in the same
+// order, but walks a template's includes after all of its directives have run
+// rather than at each directive.
 type Walker struct {
-	// Buffer stores the processed template content
+	// Buffer stores the processed template content.  If nil, Walk creates one
+	// (which can still be read after the walk).
 	Buffer *bytes.Buffer
 
 	// Loader is used to resolve and load template dependencies
@@ -29,9 +64,37 @@ type Walker struct {
 	EnteringTemplate func(template *Template) (skip bool, err error)
 
 	// ProcessedTemplate is called after a template and all its children
-	// have been processed. This allows for custom post-processing.
+	// have been processed (see the Walker docs for the exact order). This
+	// allows for custom post-processing.
 	ProcessedTemplate func(template *Template) error
 
+	// Data is passed as the data (dot) when executing directives, so
+	// directives can be chosen conditionally, eg {{# if .UseNewLayout #}}.
+	Data any
+
+	// TrimDirectiveLines removes lines containing only directives (along with
+	// their newline) so they don't leave blank lines in ParsedSource.
+	TrimDirectiveLines bool
+
+	// Bundles maps bundle names to the files included by a
+	// {{# bundle "name" #}} directive (see TemplateGroup.RegisterBundle).
+	Bundles map[string][]string
+
+	// MaxDepth, if positive, stops the walk from descending more than
+	// MaxDepth levels of includes/namespaces below the root, for partial
+	// analysis of large trees.  Deeper includes are skipped (not loaded or
+	// walked) and reported as "unexpanded" WalkTrace events.  This is separate
+	// from cycle detection, which always applies.
+	MaxDepth int
+
+	// WalkTrace, if set, receives a JSON encoded WalkEvent (one per line) for
+	// each step of the walk, giving a replayable trace of how a template was
+	// composed.
+	WalkTrace io.Writer
+
+	// depth is the include depth of the template currently being walked
+	depth int
+
 	// inProgress tracks templates currently being processed to detect cycles (infinite recursion)
 	inProgress map[string]bool
 }
@@ -40,7 +103,14 @@ type Walker struct {
 // This means includes are processed as soon as they are encountered in the template.
 // After processing, the template's ParsedSource will contain the processed content.
 // If ProcessedTemplate is defined, it will be called on each processed template.
+// If a callback returns ErrStopWalk, the walk ends there and Walk returns nil.
 func (w *Walker) Walk(root *Template) (err error) {
+	defer func() {
+		// Only the outermost Walk swallows ErrStopWalk so it unwinds nested walks
+		if w.depth == 0 && errors.Is(err, ErrStopWalk) {
+			err = nil
+		}
+	}()
 	if w.Buffer == nil {
 		w.Buffer = bytes.NewBufferString("")
 	}
@@ -52,6 +122,7 @@ func (w *Walker) Walk(root *Template) (err error) {
 	if root.Path != "" {
 		if w.inProgress[root.Path] {
 			slog.Warn("cycle detected, skipping template already in progress", "path", root.Path)
+			w.trace(WalkEvent{Event: "cycle", Template: root.Path})
 			return nil
 		}
 		w.inProgress[root.Path] = true
@@ -60,20 +131,35 @@ func (w *Walker) Walk(root *Template) (err error) {
 	// An Inorder walk of of a template.  Unlike WalkTemplate which applies a PostOrder traversal (first collects all
 	// includes, processes them and then the root template), here we will process an included template as soon as it is
 	// encountered.
-	cwd := root.Path
-	if cwd != "" {
-		cwd = filepath.Dir(cwd)
-	}
+	cwd := root.includeDir()
 
+	w.trace(WalkEvent{Event: "enter", Template: root.sourceName(), Namespace: root.Namespace})
 	if w.EnteringTemplate != nil {
 		skip, err := w.EnteringTemplate(root)
 		if skip || err != nil {
+			w.trace(WalkEvent{Event: "skip", Template: root.sourceName(), Error: errorString(err)})
 			return err
 		}
 	}
 
+	// Reset state recorded by a previous walk of this template so that walking
+	// it again (eg rendering the same root twice) re-processes its includes
+	root.includes = nil
+	root.Extensions = nil
+	root.Overrides = nil
+	root.Usings = nil
+	root.Requires = nil
+	root.FuncBindings = nil
+	root.resetDirectiveMeta()
+
 	// parse the template and render it
 	fm := ttmpl.FuncMap{
+		"requires": func(keys ...string) string {
+			// Syntax: requires "Key" "Nested.Key" ...
+			// Declares data keys that must be present (non-nil) when rendering.
+			root.Requires = append(root.Requires, keys...)
+			return fmt.Sprintf("{{/* Requires: %v */}}", keys)
+		},
 		"include": func(args ...string) (string, error) {
 			// Syntax: include "file.html" ["template1" "template2" ...]
 			// If no templates specified, includes all templates from the file.
@@ -93,26 +179,88 @@ func (w *Walker) Walk(root *Template) (err error) {
 				return fmt.Sprintf("{{/* Finished Including: '%s' */}}", glob), err
 			}
 		},
-		"namespace": func(args ...string) (string, error) {
-			// Syntax: namespace "NS" "file.html" ["template1" "template2" ...]
-			// Loads templates into namespace NS with tree-shaking.
-			if len(args) < 2 {
-				return "", fmt.Errorf("namespace requires: namespace file [templates...]")
+		"bundle": func(name string) (string, error) {
+			// Syntax: bundle "forms"
+			// Includes every file registered for the bundle, in order.
+			files, ok := w.Bundles[name]
+			if !ok {
+				return "", fmt.Errorf("bundle not found: %s", name)
 			}
-			namespace, glob := args[0], args[1]
-			if namespace == "" {
-				return "", fmt.Errorf("namespace requires a non-empty namespace name")
+			for _, file := range files {
+				if _, err := w.processInclude(root, file, nil, cwd); err != nil {
+					return "", err
+				}
 			}
-			var entryPoints []string
-			if len(args) > 2 {
-				entryPoints = args[2:]
+			return fmt.Sprintf("{{/* Included bundle: '%s' */}}", name), nil
+		},
+		"raw_include": func(file string) (string, error) {
+			// Syntax: raw_include "logo.svg"
+			// Inserts the file's contents verbatim, without parsing it as a template.
+			w.trace(WalkEvent{Event: "raw_include", Template: root.sourceName(), File: file})
+			return rawInclude(w.Loader, file, cwd)
+		},
+		"raw_include_html": func(file string) (string, error) {
+			// Syntax: raw_include_html "icon.svg"
+			// Like raw_include but typed for the html escaper (also raw_include_js/css).
+			w.trace(WalkEvent{Event: "raw_include", Template: root.sourceName(), File: file})
+			return typedRawInclude(w.Loader, "html", file, cwd)
+		},
+		"raw_include_js": func(file string) (string, error) {
+			w.trace(WalkEvent{Event: "raw_include", Template: root.sourceName(), File: file})
+			return typedRawInclude(w.Loader, "js", file, cwd)
+		},
+		"raw_include_css": func(file string) (string, error) {
+			w.trace(WalkEvent{Event: "raw_include", Template: root.sourceName(), File: file})
+			return typedRawInclude(w.Loader, "css", file, cwd)
+		},
+		// Syntax: namespace "NS" "file.html" ["template1" "template2" ...]
+		// Loads templates into namespace NS with tree-shaking.  include_ns is
+		// the same directive, reading as an include with an explicit namespace
+		// (rather than one inherited from a namespaced parent).
+		"namespace":  w.namespaceDirective(root, cwd),
+		"include_ns": w.namespaceDirective(root, cwd),
+		// Syntax: bindfunc "usd" "currency" "USD"
+		// Creates usd, which calls currency with "USD" as its first argument.
+		"bindfunc": root.bindFuncDirective,
+		"default":  root.defaultDirective,
+		"meta": func(key string, value string) string {
+			// Syntax: meta "key" "value"
+			// Records inline metadata on the template without affecting its output.
+			root.setDirectiveMeta(key, value)
+			return fmt.Sprintf("{{/* Meta: '%s' */}}", key)
+		},
+		"override": func(args ...string) (string, error) {
+			// Syntax: override "NS:original" "replacement" ["NS:other" "replacement2" ...]
+			// Rewires every reference to original (in all templates) to replacement.
+			if len(args) < 2 || len(args)%2 != 0 {
+				return "", fmt.Errorf("override requires pairs of: originalTemplate replacementTemplate")
 			}
-			skipped, err := w.processNamespace(root, namespace, glob, entryPoints, cwd)
-			if skipped {
-				return fmt.Sprintf("{{/* Skipping namespace '%s' from '%s' */}}", namespace, glob), err
-			} else {
-				return fmt.Sprintf("{{/* Loaded namespace '%s' from '%s' */}}", namespace, glob), err
+			for i := 0; i < len(args); i += 2 {
+				if args[i] == "" || args[i+1] == "" {
+					return "", fmt.Errorf("override requires non-empty template names")
+				}
+				if root.Overrides == nil {
+					root.Overrides = make(map[string]string)
+				}
+				root.Overrides[args[i]] = args[i+1]
+			}
+			w.trace(WalkEvent{Event: "override", Template: root.sourceName(), Rewrites: root.Overrides})
+			return fmt.Sprintf("{{/* Overrode '%s' */}}", args[0]), nil
+		},
+		"using": func(namespaces ...string) (string, error) {
+			// Syntax: using "Namespace" ...
+			// Aliases every template of an imported namespace without its prefix.
+			if len(namespaces) == 0 {
+				return "", fmt.Errorf("using requires at least one namespace")
 			}
+			for _, namespace := range namespaces {
+				if namespace == "" {
+					return "", fmt.Errorf("using requires non-empty namespace names")
+				}
+				root.Usings = append(root.Usings, namespace)
+				w.trace(WalkEvent{Event: "using", Template: root.sourceName(), Namespace: namespace})
+			}
+			return fmt.Sprintf("{{/* Using namespace '%s' */}}", strings.Join(namespaces, "', '")), nil
 		},
 		"extend": func(args ...string) (string, error) {
 			// Syntax: extend "SourceTemplate" "DestTemplate" "block1" "override1" ...
@@ -125,9 +273,6 @@ func (w *Walker) Walk(root *Template) (err error) {
 				return "", fmt.Errorf("extend requires pairs of block/override after destTemplate")
 			}
 			source, dest := args[0], args[1]
-			if dest == "" {
-				return "", fmt.Errorf("extend requires a non-empty destination template name")
-			}
 
 			// Parse block/override pairs
 			rewrites := make(map[string]string)
@@ -136,23 +281,45 @@ func (w *Walker) Walk(root *Template) (err error) {
 				rewrites[block] = override
 			}
 
-			w.processExtend(root, source, dest, rewrites)
+			if err := w.processExtend(root, source, dest, rewrites); err != nil {
+				return "", err
+			}
 			return fmt.Sprintf("{{/* Extended '%s' as '%s' */}}", source, dest), nil
 		},
 	}
 
-	templ, err := ttmpl.New("").Funcs(fm).Delims("{{#", "#}}").Parse(string(root.RawSource))
+	// Plain Go templates pass through unchanged, without parsing
+	if !UsesDirectives(root.RawSource) {
+		w.Buffer.Write(root.RawSource)
+		return w.finishWalk(root)
+	}
+
+	source := expandVerbatim(string(root.RawSource))
+	if w.TrimDirectiveLines {
+		source = trimDirectiveLines(source)
+	}
+	templ, err := ttmpl.New("").Funcs(fm).Delims("{{#", "#}}").Parse(source)
 	if err != nil {
 		slog.Error("error preprocessing template: ", "path", root.Path, "error", err)
 		return panicOrError(err)
 	}
-	if err := templ.Execute(w.Buffer, nil); err != nil {
+	if err := templ.Execute(w.Buffer, w.Data); err != nil {
+		if errors.Is(err, ErrStopWalk) {
+			return ErrStopWalk
+		}
 		slog.Error("error preprocessing template: ", "path", root.Path, "error", err)
 		root.Error = err
+		w.trace(WalkEvent{Event: "error", Template: root.sourceName(), Error: err.Error()})
 		return panicOrError(err)
-	} else {
-		root.ParsedSource = w.Buffer.String()
 	}
+	return w.finishWalk(root)
+}
+
+// finishWalk records root's ParsedSource once its directives have run and
+// reports it as processed.
+func (w *Walker) finishWalk(root *Template) error {
+	root.ParsedSource = w.Buffer.String()
+	w.trace(WalkEvent{Event: "processed", Template: root.sourceName()})
 
 	// No handle this template
 	if w.ProcessedTemplate != nil {
@@ -161,6 +328,51 @@ func (w *Walker) Walk(root *Template) (err error) {
 	return nil
 }
 
+// directiveLineRegex matches a line holding only directives (and whitespace),
+// capturing the directives.
+var directiveLineRegex = regexp.MustCompile(`(?m)^[ \t]*((?:\{\{#.*?#\}\}[ \t]*)+)\r?\n`)
+
+// trimDirectiveLines removes the whitespace and trailing newline of lines
+// that only contain directives, keeping the directives themselves.
+func trimDirectiveLines(source string) string {
+	return directiveLineRegex.ReplaceAllStringFunc(source, func(line string) string {
+		return strings.Join(directiveRegex.FindAllString(line, -1), "")
+	})
+}
+
+// rawInclude loads file and returns its contents with template delimiters
+// escaped, so that it passes through template parsing and renders verbatim.
+// Note that in html mode the contents are emitted as trusted markup.
+func rawInclude(loader TemplateLoader, file string, cwd string) (string, error) {
+	files, err := loader.Load(file, cwd)
+	if err != nil {
+		slog.Error("error loading raw include: ", "included", file, "error", err)
+		return "", panicOrError(err)
+	}
+	var sb strings.Builder
+	for _, f := range files {
+		sb.WriteString(escapeActions(string(f.RawSource)))
+	}
+	return sb.String(), nil
+}
+
+// typedRawInclude loads file and returns an action that emits its contents
+// through the rawContent builtin, so that html/template treats them as
+// trusted content of the given kind (html, js or css) rather than escaping
+// them for the context they appear in.
+func typedRawInclude(loader TemplateLoader, kind string, file string, cwd string) (string, error) {
+	files, err := loader.Load(file, cwd)
+	if err != nil {
+		slog.Error("error loading raw include: ", "included", file, "error", err)
+		return "", panicOrError(err)
+	}
+	var sb strings.Builder
+	for _, f := range files {
+		fmt.Fprintf(&sb, "{{ rawContent %q %q }}", kind, f.RawSource)
+	}
+	return sb.String(), nil
+}
+
 // processInclude handles the inclusion of another template within the current template.
 // If FoundInclude returns true, the include is skipped. Otherwise, the included template
 // and its dependencies are loaded and processed.
@@ -168,7 +380,11 @@ func (w *Walker) Walk(root *Template) (err error) {
 // If entryPoints is non-empty, only those templates (and their dependencies) are included.
 // Returns a boolean indicating if the include was skipped, and any error encountered.
 func (w *Walker) processInclude(root *Template, included string, entryPoints []string, cwd string) (skipped bool, err error) {
+	if w.depthExceeded(root, included) {
+		return true, nil
+	}
 	skipped = w.FoundInclude != nil && w.FoundInclude(included)
+	w.trace(WalkEvent{Event: "include", Template: root.sourceName(), File: included, EntryPoints: entryPoints, Skipped: skipped})
 	if skipped {
 		return
 	}
@@ -179,9 +395,12 @@ func (w *Walker) processInclude(root *Template, included string, entryPoints []s
 		return false, panicOrError(err)
 	}
 	for _, child := range children {
-		// Inherit namespace from parent template
+		// Inherit namespace from parent template, so a file included by a
+		// namespaced file is namespaced with it (at any depth).  Use
+		// include_ns to include into a different namespace.
 		if root.Namespace != "" {
 			child.Namespace = root.Namespace
+			child.NamespacePath = root.NamespacePath
 		}
 
 		// Set entry points for selective inclusion (tree-shaking)
@@ -192,6 +411,7 @@ func (w *Walker) processInclude(root *Template, included string, entryPoints []s
 		if child.Path != "" {
 			if !root.AddDependency(child) {
 				slog.Error(fmt.Sprintf("found cyclical dependency: %s -> %s", child.Path, root.Path), "from", child.Path, "to", root.Path)
+				w.trace(WalkEvent{Event: "cycle", Template: root.sourceName(), File: child.Path})
 				continue
 			}
 		}
@@ -200,16 +420,16 @@ func (w *Walker) processInclude(root *Template, included string, entryPoints []s
 		// with its own buffer. This ensures the child's ParsedSource contains only
 		// its own content, not contaminated with the parent's partial buffer content.
 		if child.Namespace != "" {
-			childWalker := &Walker{
-				Loader:            w.Loader,
-				FoundInclude:      w.FoundInclude,
-				EnteringTemplate:  w.EnteringTemplate,
-				ProcessedTemplate: w.ProcessedTemplate,
-				inProgress:        w.inProgress, // Share inProgress map for cycle detection
-			}
+			childWalker := w.childWalker()
 			err = childWalker.Walk(child)
+			putBuffer(childWalker.Buffer)
 		} else {
+			w.depth++
 			err = w.Walk(child)
+			w.depth--
+		}
+		if errors.Is(err, ErrStopWalk) {
+			return false, err
 		}
 		if err != nil {
 			slog.Error("error walking", "included", included, "error", err)
@@ -220,16 +440,51 @@ func (w *Walker) processInclude(root *Template, included string, entryPoints []s
 	return
 }
 
+// namespaceDirective returns the implementation of the namespace (and
+// include_ns) directive for root.
+func (w *Walker) namespaceDirective(root *Template, cwd string) func(args ...string) (string, error) {
+	return func(args ...string) (string, error) {
+		if len(args) < 2 {
+			return "", fmt.Errorf("namespace requires: namespace file [templates...]")
+		}
+		namespace, glob := args[0], args[1]
+		if namespace == "" {
+			return "", fmt.Errorf("namespace requires a non-empty namespace name")
+		}
+		var entryPoints []string
+		if len(args) > 2 {
+			entryPoints = args[2:]
+		}
+		skipped, err := w.processNamespace(root, namespace, glob, entryPoints, cwd)
+		// A glob like "a/*/b.html" would otherwise end the comment early
+		label := strings.ReplaceAll(glob, "*/", "*\\/")
+		if skipped {
+			return fmt.Sprintf("{{/* Skipping namespace '%s' from '%s' */}}", namespace, label), err
+		} else {
+			return fmt.Sprintf("{{/* Loaded namespace '%s' from '%s' */}}", namespace, label), err
+		}
+	}
+}
+
 // processNamespace handles the inclusion of templates into a namespace.
 // Templates are loaded from the file and will be registered with the given namespace prefix.
 // If entryPoints is non-empty, only those templates (and their dependencies) are included.
 func (w *Walker) processNamespace(root *Template, namespace string, included string, entryPoints []string, cwd string) (skipped bool, err error) {
+	if w.depthExceeded(root, included) {
+		return true, nil
+	}
 	skipped = w.FoundInclude != nil && w.FoundInclude(included)
+	w.trace(WalkEvent{Event: "namespace", Template: root.sourceName(), Namespace: namespace, File: included, EntryPoints: entryPoints, Skipped: skipped})
 	if skipped {
 		return
 	}
 
-	children, err := w.Loader.Load(included, cwd)
+	var children []*Template
+	if isGlobPattern(included) {
+		children, err = loadGlob(w.Loader, included)
+	} else {
+		children, err = w.Loader.Load(included, cwd)
+	}
 	if err != nil {
 		slog.Error("error loading namespace: ", "included", included, "error", err)
 		return false, panicOrError(err)
@@ -244,6 +499,7 @@ func (w *Walker) processNamespace(root *Template, namespace string, included str
 		if child.Path != "" {
 			if !root.AddDependency(child) {
 				slog.Error(fmt.Sprintf("found cyclical dependency: %s -> %s", child.Path, root.Path), "from", child.Path, "to", root.Path)
+				w.trace(WalkEvent{Event: "cycle", Template: root.sourceName(), File: child.Path})
 				continue
 			}
 		}
@@ -253,14 +509,12 @@ func (w *Walker) processNamespace(root *Template, namespace string, included str
 		// avoiding conflicts when the same template is included multiple times
 		// with different namespaces.
 		// IMPORTANT: Share the inProgress map to detect cycles (infinite recursion).
-		childWalker := &Walker{
-			Loader:            w.Loader,
-			FoundInclude:      w.FoundInclude,
-			EnteringTemplate:  w.EnteringTemplate,
-			ProcessedTemplate: w.ProcessedTemplate,
-			inProgress:        w.inProgress, // Share inProgress map for cycle detection
-		}
+		childWalker := w.childWalker()
 		err = childWalker.Walk(child)
+		putBuffer(childWalker.Buffer)
+		if errors.Is(err, ErrStopWalk) {
+			return false, err
+		}
 		if err != nil {
 			slog.Error("error walking namespace", "included", included, "error", err)
 			root.Error = err
@@ -270,13 +524,100 @@ func (w *Walker) processNamespace(root *Template, namespace string, included str
 	return
 }
 
-// processExtend records an extend directive on the root template.
+// childWalker returns a walker with the same settings as w but its own
+// buffer, for walking templates one level deeper.  The buffer is taken from
+// the pool and must be released with putBuffer once the child is walked.
+// The inProgress map is shared for cycle detection.
+func (w *Walker) childWalker() *Walker {
+	return &Walker{
+		Buffer:             getBuffer(),
+		Loader:             w.Loader,
+		FoundInclude:       w.FoundInclude,
+		EnteringTemplate:   w.EnteringTemplate,
+		ProcessedTemplate:  w.ProcessedTemplate,
+		Data:               w.Data,
+		TrimDirectiveLines: w.TrimDirectiveLines,
+		Bundles:            w.Bundles,
+		WalkTrace:          w.WalkTrace,
+		MaxDepth:           w.MaxDepth,
+		depth:              w.depth + 1,
+		inProgress:         w.inProgress,
+	}
+}
+
+// depthExceeded reports whether an include of included from root would go
+// beyond MaxDepth, tracing it as unexpanded if so.
+func (w *Walker) depthExceeded(root *Template, included string) bool {
+	if w.MaxDepth <= 0 || w.depth < w.MaxDepth {
+		return false
+	}
+	w.trace(WalkEvent{Event: "unexpanded", Template: root.sourceName(), File: included})
+	return true
+}
+
+// processExtend validates an extend directive and records it on the root
+// template.
 // The actual extension (copying and rewiring) is performed later in group.go
 // after all templates have been parsed.
-func (w *Walker) processExtend(root *Template, source string, dest string, rewrites map[string]string) {
-	root.Extensions = append(root.Extensions, Extension{
-		SourceTemplate: source,
-		DestTemplate:   dest,
-		Rewrites:       rewrites,
-	})
+func (w *Walker) processExtend(root *Template, source string, dest string, rewrites map[string]string) error {
+	ext, err := NewExtension(source, dest, rewrites)
+	if err != nil {
+		return err
+	}
+	root.Extensions = append(root.Extensions, ext)
+	w.trace(WalkEvent{Event: "extend", Template: root.sourceName(), Source: source, Dest: dest, Rewrites: rewrites})
+	return nil
+}
+
+// WalkEvent is a single step of a walk, as written to Walker.WalkTrace.
+type WalkEvent struct {
+	// Event is one of "enter", "skip", "include", "raw_include", "namespace",
+	// "unexpanded", "extend", "override", "using", "cycle", "error" or
+	// "processed".
+	Event string `json:"event"`
+
+	// Template is the path (or name) of the template being walked.
+	Template string `json:"template,omitempty"`
+
+	// Namespace is the namespace of the template being entered, being
+	// loaded by a namespace directive or named by a using directive.
+	Namespace string `json:"namespace,omitempty"`
+
+	// File is the file named by an include or namespace directive, or the
+	// dependency that would have created a cycle.
+	File string `json:"file,omitempty"`
+
+	// EntryPoints are the templates selected by an include or namespace.
+	EntryPoints []string `json:"entryPoints,omitempty"`
+
+	// Skipped is set if FoundInclude skipped an include or namespace.
+	Skipped bool `json:"skipped,omitempty"`
+
+	// Source and Dest are the templates of an extend directive.
+	Source string `json:"source,omitempty"`
+	Dest   string `json:"dest,omitempty"`
+
+	// Rewrites are the template rewrites of an extend or override directive.
+	Rewrites map[string]string `json:"rewrites,omitempty"`
+
+	// Error describes why a template failed or was skipped.
+	Error string `json:"error,omitempty"`
+}
+
+// trace writes ev to the walk trace, if any.
+func (w *Walker) trace(ev WalkEvent) {
+	if w.WalkTrace == nil {
+		return
+	}
+	if err := json.NewEncoder(w.WalkTrace).Encode(ev); err != nil {
+		slog.Warn("error writing walk trace", "error", err)
+	}
+}
+
+// errorString returns err's message, or "" if err is nil.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
 }